@@ -6,6 +6,8 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// TestTypesetBytes checks whole rows: glyphs are padded out to their full
+// width, so rows keep their trailing spaces.
 func TestTypesetBytes(t *testing.T) {
 	tests := []struct {
 		name string
@@ -14,42 +16,42 @@ func TestTypesetBytes(t *testing.T) {
 		want [][]byte
 	}{
 		{"render T", "T", nil, [][]byte{
-			[]byte(" ######"),
-			[]byte("    #"),
-			[]byte("    #"),
-			[]byte("    #"),
-			[]byte("    #"),
-			[]byte("    #"),
-			[]byte("    #"),
-			[]byte("    #"),
+			[]byte(" ###### "),
+			[]byte("    #   "),
+			[]byte("    #   "),
+			[]byte("    #   "),
+			[]byte("    #   "),
+			[]byte("    #   "),
+			[]byte("    #   "),
+			[]byte("    #   "),
 		}},
 		{"render Tricia", "Tricia", nil, [][]byte{
-			[]byte(" ######"),
-			[]byte("    #"),
-			[]byte("    #              #               #      ###"),
-			[]byte("    #    # ##             ###            #   #"),
-			[]byte("    #    ##  #           #   #             ###"),
-			[]byte("    #    #   #     #     #         #      #  #"),
-			[]byte("    #    #         #     #   #     #     #   #"),
-			[]byte("    #    #         #      ###      #      ### #"),
+			[]byte(" ######                                         "),
+			[]byte("    #                                           "),
+			[]byte("    #              #               #      ###   "),
+			[]byte("    #    # ##             ###            #   #  "),
+			[]byte("    #    ##  #           #   #             ###  "),
+			[]byte("    #    #   #     #     #         #      #  #  "),
+			[]byte("    #    #         #     #   #     #     #   #  "),
+			[]byte("    #    #         #      ###      #      ### # "),
 		}},
-		{"render T 2X", "T", []TypesetOpts{{2, false}}, [][]byte{
-			[]byte("  ############"),
-			[]byte("  ############"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
-			[]byte("        ##"),
+		{"render T 2X", "T", []TypesetOpts{{Scale: 2}}, [][]byte{
+			[]byte("  ############  "),
+			[]byte("  ############  "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
+			[]byte("        ##      "),
 		}},
 	}
 	for _, tt := range tests {
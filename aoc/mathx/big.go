@@ -0,0 +1,100 @@
+package mathx
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// BigMod returns a modulo m, in [0, |m|), as a new Int.
+func BigMod(a, m *big.Int) *big.Int {
+	// big.Int.Mod is Euclidean, so it's never negative
+	return new(big.Int).Mod(a, m)
+}
+
+// BigGCD returns the greatest common divisor of a and b, which is never
+// negative.
+func BigGCD(a, b *big.Int) *big.Int {
+	return new(big.Int).GCD(nil, nil, new(big.Int).Abs(a), new(big.Int).Abs(b))
+}
+
+// BigLCM returns the least common multiple of the values, which is never
+// negative. The LCM of nothing is 1; of anything including 0, 0.
+func BigLCM(vals ...*big.Int) *big.Int {
+	ret := big.NewInt(1)
+	for _, v := range vals {
+		if v.Sign() == 0 {
+			return new(big.Int)
+		}
+		v := new(big.Int).Abs(v)
+		ret.Mul(ret.Quo(ret, BigGCD(ret, v)), v)
+	}
+	return ret
+}
+
+// BigExtGCD returns g = GCD(a, b) along with Bézout coefficients x and y such
+// that a*x + b*y = g.
+func BigExtGCD(a, b *big.Int) (g, x, y *big.Int) {
+	oldR, r := new(big.Int).Set(a), new(big.Int).Set(b)
+	oldS, s := big.NewInt(1), big.NewInt(0)
+	oldT, t := big.NewInt(0), big.NewInt(1)
+	q, tmp := new(big.Int), new(big.Int)
+	for r.Sign() != 0 {
+		q.Quo(oldR, r)
+		oldR, r = r, oldR.Sub(oldR, tmp.Mul(q, r))
+		oldS, s = s, oldS.Sub(oldS, tmp.Mul(q, s))
+		oldT, t = t, oldT.Sub(oldT, tmp.Mul(q, t))
+	}
+	if oldR.Sign() < 0 {
+		return oldR.Neg(oldR), oldS.Neg(oldS), oldT.Neg(oldT)
+	}
+	return oldR, oldS, oldT
+}
+
+// BigModInverse returns x in [0, |m|) such that a*x = 1 modulo m, or
+// ErrNoInverse if a and m aren't coprime.
+func BigModInverse(a, m *big.Int) (*big.Int, error) {
+	g, x, _ := BigExtGCD(BigMod(a, m), m)
+	if g.Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("%v mod %v: %w", a, m, ErrNoInverse)
+	}
+	return x.Mod(x, m), nil
+}
+
+// BigModPow returns base**exp modulo m, in [0, |m|). exp must not be negative.
+func BigModPow(base, exp, m *big.Int) *big.Int {
+	if exp.Sign() < 0 {
+		panic(fmt.Sprintf("mathx.BigModPow: negative exponent %v", exp))
+	}
+	m = new(big.Int).Abs(m)
+	return new(big.Int).Exp(BigMod(base, m), exp, m)
+}
+
+// BigCRT is CRT for big.Int; since big.Int can't overflow, the only errors are
+// inconsistent congruences and bad arguments.
+func BigCRT(residues, moduli []*big.Int) (x, m *big.Int, err error) {
+	if len(residues) != len(moduli) {
+		return nil, nil, fmt.Errorf("%d residues but %d moduli", len(residues), len(moduli))
+	}
+	x, m = big.NewInt(0), big.NewInt(1)
+	for i, mi := range moduli {
+		if mi.Sign() == 0 {
+			return nil, nil, fmt.Errorf("modulus %d is zero", i)
+		}
+		mi := new(big.Int).Abs(mi)
+
+		// x + m*k = ri (mod mi)  =>  m*k = ri - x (mod mi)
+		g, p, _ := BigExtGCD(m, mi)
+		diff := BigMod(new(big.Int).Sub(residues[i], x), mi)
+		if new(big.Int).Rem(diff, g).Sign() != 0 {
+			return nil, nil, fmt.Errorf("x = %v mod %v: %w", residues[i], moduli[i], ErrNoSolution)
+		}
+		step := mi.Quo(mi, g)
+		k := diff.Quo(diff, g)
+		k.Mul(k, p).Mod(k, step)
+
+		x.Add(x, k.Mul(k, m))
+		m.Mul(m, step)
+		x.Mod(x, m)
+	}
+	return x, m, nil
+}
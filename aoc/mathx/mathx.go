@@ -0,0 +1,182 @@
+// Package mathx has number theory helpers: GCD and LCM, modular inverses and
+// powers, and the Chinese remainder theorem. Each comes in a generic version for
+// the built-in signed integers, with overflow checking where results can grow,
+// and a Big version for *big.Int.
+package mathx
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"golang.org/x/exp/constraints"
+)
+
+var (
+	ErrOverflow   = errors.New("integer overflow")
+	ErrNoInverse  = errors.New("no modular inverse")
+	ErrNoSolution = errors.New("no solution")
+)
+
+// Mod returns a modulo m, in [0, |m|).
+func Mod[T constraints.Signed](a, m T) T {
+	a %= m
+	if a < 0 {
+		if m < 0 {
+			m = -m
+		}
+		a += m
+	}
+	return a
+}
+
+// MulChecked returns a*b, and whether it fit in T.
+func MulChecked[T constraints.Signed](a, b T) (T, bool) {
+	if b == -1 {
+		// only the most negative T is its own negation, besides 0
+		return -a, a == 0 || -a != a
+	}
+	p := a * b
+	return p, b == 0 || p/b == a
+}
+
+// AddChecked returns a+b, and whether it fit in T.
+func AddChecked[T constraints.Signed](a, b T) (T, bool) {
+	s := a + b
+	return s, (b >= 0) == (s >= a)
+}
+
+// GCD returns the greatest common divisor of a and b, which is never negative.
+// GCD(0, 0) is 0.
+func GCD[T constraints.Signed](a, b T) T {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// LCM returns the least common multiple of the values, which is never negative.
+// The LCM of nothing is 1; of anything including 0, 0. It returns ErrOverflow
+// if the result doesn't fit in T.
+func LCM[T constraints.Signed](vals ...T) (T, error) {
+	ret := T(1)
+	for _, v := range vals {
+		if v == 0 {
+			return 0, nil
+		}
+		if v < 0 {
+			v = -v
+		}
+		var ok bool
+		ret, ok = MulChecked(ret/GCD(ret, v), v)
+		if !ok || ret < 0 {
+			return 0, fmt.Errorf("lcm of %v: %w", vals, ErrOverflow)
+		}
+	}
+	return ret, nil
+}
+
+// ExtGCD returns g = GCD(a, b) along with Bézout coefficients x and y such that
+// a*x + b*y = g.
+func ExtGCD[T constraints.Signed](a, b T) (g, x, y T) {
+	oldR, r := a, b
+	oldS, s := T(1), T(0)
+	oldT, t := T(0), T(1)
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldS, s = s, oldS-q*s
+		oldT, t = t, oldT-q*t
+	}
+	if oldR < 0 {
+		return -oldR, -oldS, -oldT
+	}
+	return oldR, oldS, oldT
+}
+
+// MulMod returns a*b modulo m, in [0, |m|), without overflowing.
+func MulMod[T constraints.Signed](a, b, m T) T {
+	if m < 0 {
+		m = -m
+	}
+	a, b = Mod(a, m), Mod(b, m)
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	return T(bits.Rem64(hi, lo, uint64(m)))
+}
+
+// ModInverse returns x in [0, |m|) such that a*x = 1 modulo m, or ErrNoInverse
+// if a and m aren't coprime.
+func ModInverse[T constraints.Signed](a, m T) (T, error) {
+	g, x, _ := ExtGCD(Mod(a, m), m)
+	if g != 1 {
+		return 0, fmt.Errorf("%v mod %v: %w", a, m, ErrNoInverse)
+	}
+	return Mod(x, m), nil
+}
+
+// ModPow returns base**exp modulo m, in [0, |m|). exp must not be negative.
+func ModPow[T constraints.Signed](base, exp, m T) T {
+	if exp < 0 {
+		panic(fmt.Sprintf("mathx.ModPow: negative exponent %v", exp))
+	}
+	if m < 0 {
+		m = -m
+	}
+	ret := Mod(1, m)
+	base = Mod(base, m)
+	for ; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			ret = MulMod(ret, base, m)
+		}
+		base = MulMod(base, base, m)
+	}
+	return ret
+}
+
+// CRT finds x such that x = residues[i] modulo moduli[i] for every i, using the
+// Chinese remainder theorem. The moduli need not be coprime. It returns the
+// smallest non-negative x and the modulus of the solution, i.e. the LCM of the
+// moduli; every solution is x plus a multiple of it.
+//
+// It returns ErrNoSolution if the congruences are inconsistent, and ErrOverflow
+// if the LCM of the moduli doesn't fit in T.
+func CRT[T constraints.Signed](residues, moduli []T) (x, m T, err error) {
+	if len(residues) != len(moduli) {
+		return 0, 0, fmt.Errorf("%d residues but %d moduli", len(residues), len(moduli))
+	}
+	x, m = 0, 1
+	for i, mi := range moduli {
+		if mi == 0 {
+			return 0, 0, fmt.Errorf("modulus %d is zero", i)
+		}
+		if mi < 0 {
+			mi = -mi
+		}
+		ri := Mod(residues[i], mi)
+
+		// x + m*k = ri (mod mi)  =>  m*k = ri - x (mod mi)
+		g, p, _ := ExtGCD(m, mi)
+		diff := Mod(ri-Mod(x, mi), mi)
+		if diff%g != 0 {
+			return 0, 0, fmt.Errorf("x = %v mod %v: %w", residues[i], moduli[i], ErrNoSolution)
+		}
+		step := mi / g
+		k := MulMod(diff/g, p, step)
+
+		lcm, ok := MulChecked(m, step)
+		if !ok || lcm < 0 {
+			return 0, 0, fmt.Errorf("crt of moduli %v: %w", moduli, ErrOverflow)
+		}
+		// x + m*k, without overflowing; both terms are below lcm
+		if y := MulMod(m, k, lcm); x >= lcm-y {
+			x -= lcm - y
+		} else {
+			x += y
+		}
+		m = lcm
+	}
+	return x, m, nil
+}
@@ -0,0 +1,166 @@
+package mathx
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCDAndLCM(t *testing.T) {
+	tests := []struct {
+		a, b     int64
+		gcd, lcm int64
+	}{
+		{12, 18, 6, 36},
+		{-12, 18, 6, 36},
+		{7, 13, 1, 91},
+		{0, 5, 5, 0},
+		{0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.gcd, GCD(tt.a, tt.b), "GCD(%d, %d)", tt.a, tt.b)
+		lcm, err := LCM(tt.a, tt.b)
+		require.NoError(t, err)
+		require.Equal(t, tt.lcm, lcm, "LCM(%d, %d)", tt.a, tt.b)
+
+		require.Equal(t, big.NewInt(tt.gcd), BigGCD(big.NewInt(tt.a), big.NewInt(tt.b)))
+		require.Equal(t, big.NewInt(tt.lcm), BigLCM(big.NewInt(tt.a), big.NewInt(tt.b)))
+	}
+
+	// the day 11 example's divisors
+	lcm, err := LCM[int64](23, 19, 13, 17)
+	require.NoError(t, err)
+	require.Equal(t, int64(96577), lcm)
+
+	_, err = LCM[int32](65537, 65539)
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestMulChecked(t *testing.T) {
+	tests := []struct {
+		a, b int64
+		ok   bool
+	}{
+		{3, 4, true},
+		{math.MaxInt64, 1, true},
+		{math.MaxInt64, 2, false},
+		{math.MinInt64, -1, false},
+		{-1, math.MinInt64, false},
+		{math.MinInt64, 1, true},
+		{1 << 31, 1 << 31, true},
+		{1 << 32, 1 << 31, false},
+		{0, math.MinInt64, true},
+	}
+	for _, tt := range tests {
+		p, ok := MulChecked(tt.a, tt.b)
+		require.Equal(t, tt.ok, ok, "%d * %d", tt.a, tt.b)
+		if ok {
+			require.Equal(t, tt.a*tt.b, p)
+		}
+	}
+
+	_, ok := AddChecked[int64](math.MaxInt64, 1)
+	require.False(t, ok)
+	_, ok = AddChecked[int64](math.MinInt64, -1)
+	require.False(t, ok)
+	s, ok := AddChecked[int64](math.MaxInt64, -1)
+	require.True(t, ok)
+	require.Equal(t, int64(math.MaxInt64-1), s)
+}
+
+func TestModular(t *testing.T) {
+	require.Equal(t, int64(2), Mod[int64](-7, 3))
+	require.Equal(t, int64(2), Mod[int64](-7, -3))
+
+	g, x, y := ExtGCD[int64](240, 46)
+	require.Equal(t, int64(2), g)
+	require.Equal(t, g, 240*x+46*y)
+
+	inv, err := ModInverse[int64](3, 11)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), inv)
+	_, err = ModInverse[int64](4, 8)
+	require.ErrorIs(t, err, ErrNoInverse)
+
+	binv, err := BigModInverse(big.NewInt(3), big.NewInt(11))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(4), binv)
+	_, err = BigModInverse(big.NewInt(4), big.NewInt(8))
+	require.ErrorIs(t, err, ErrNoInverse)
+
+	require.Equal(t, int64(445), ModPow[int64](4, 13, 497))
+	require.Equal(t, int64(0), ModPow[int64](5, 3, 1))
+	require.Equal(t, big.NewInt(445), BigModPow(big.NewInt(4), big.NewInt(13), big.NewInt(497)))
+
+	// operands near the top of int64 shouldn't overflow
+	const p = 9223372036854775783 // the largest prime below 2**63
+	require.Equal(t, int64(1), ModPow[int64](2, p-1, p))
+	require.Equal(t, int64(1), MulMod[int64](p-1, p-1, p))
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a, b, m := rng.Int63()-rng.Int63(), rng.Int63()-rng.Int63(), rng.Int63()+1
+		want := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+		want.Mod(want, big.NewInt(m))
+		require.Equal(t, want.Int64(), MulMod(a, b, m), "%d * %d mod %d", a, b, m)
+
+		g, x, y := BigExtGCD(big.NewInt(a), big.NewInt(m))
+		sum := new(big.Int).Mul(big.NewInt(a), x)
+		sum.Add(sum, new(big.Int).Mul(big.NewInt(m), y))
+		require.Equal(t, g, sum)
+		require.Equal(t, BigGCD(big.NewInt(a), big.NewInt(m)), g)
+	}
+}
+
+func TestCRT(t *testing.T) {
+	tests := []struct {
+		name     string
+		residues []int64
+		moduli   []int64
+		x, m     int64
+		err      error
+	}{
+		{"classic", []int64{2, 3, 2}, []int64{3, 5, 7}, 23, 105, nil},
+		{"negative residue", []int64{-1, -1}, []int64{4, 9}, 35, 36, nil},
+		{"not coprime", []int64{3, 5}, []int64{4, 6}, 11, 12, nil},
+		{"inconsistent", []int64{0, 1}, []int64{4, 6}, 0, 0, ErrNoSolution},
+		{"empty", nil, nil, 0, 1, nil},
+		{"overflow", []int64{1, 2}, []int64{1<<62 + 1, 1<<62 - 1}, 0, 0, ErrOverflow},
+		{"large", []int64{1, 2}, []int64{1 << 31, 1<<31 - 1}, 2147483649, 1<<62 - 1<<31, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, m, err := CRT(tt.residues, tt.moduli)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.x, x)
+				require.Equal(t, tt.m, m)
+			}
+
+			var residues, moduli []*big.Int
+			for i := range tt.residues {
+				residues = append(residues, big.NewInt(tt.residues[i]))
+				moduli = append(moduli, big.NewInt(tt.moduli[i]))
+			}
+			bx, bm, err := BigCRT(residues, moduli)
+			if tt.err == ErrNoSolution {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			for i := range residues {
+				r := new(big.Int).Sub(bx, residues[i])
+				require.Zero(t, r.Mod(r, moduli[i]).Sign())
+			}
+			if tt.err == nil {
+				require.Equal(t, big.NewInt(tt.x), bx)
+				require.Equal(t, big.NewInt(tt.m), bm)
+			}
+		})
+	}
+}
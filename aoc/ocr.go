@@ -0,0 +1,273 @@
+package aoc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// The block letters drawn by AoC puzzles (e.g. 2022 day 10, 2016 day 8, 2021
+// day 13) come in two sizes: 4 pixels wide by 6 high, and 6 wide by 10 high.
+// Not every letter has been seen in the wild; these are the ones that have.
+var ocrLetters6 = map[rune]string{
+	'A': ".##. #..# #..# #### #..# #..#",
+	'B': "###. #..# ###. #..# #..# ###.",
+	'C': ".##. #..# #... #... #..# .##.",
+	'E': "#### #... ###. #... #... ####",
+	'F': "#### #... ###. #... #... #...",
+	'G': ".##. #..# #... #.## #..# .###",
+	'H': "#..# #..# #### #..# #..# #..#",
+	'I': ".### ..#. ..#. ..#. ..#. .###",
+	'J': "..## ...# ...# ...# #..# .##.",
+	'K': "#..# #.#. ##.. #.#. #.#. #..#",
+	'L': "#... #... #... #... #... ####",
+	'O': ".##. #..# #..# #..# #..# .##.",
+	'P': "###. #..# #..# ###. #... #...",
+	'R': "###. #..# #..# ###. #.#. #..#",
+	'S': ".### #... #... .##. ...# ###.",
+	'U': "#..# #..# #..# #..# #..# .##.",
+	'Y': "#...# #...# .#.#. ..#.. ..#.. ..#..",
+	'Z': "#### ...# ..#. .#.. #... ####",
+}
+
+var ocrLetters10 = map[rune]string{
+	'A': "..##.. .#..#. #....# #....# #....# ###### #....# #....# #....# #....#",
+	'B': "#####. #....# #....# #....# #####. #....# #....# #....# #....# #####.",
+	'C': ".####. #....# #..... #..... #..... #..... #..... #..... #....# .####.",
+	'E': "###### #..... #..... #..... #####. #..... #..... #..... #..... ######",
+	'F': "###### #..... #..... #..... #####. #..... #..... #..... #..... #.....",
+	'G': ".####. #....# #..... #..... #..... #..### #....# #....# #...## .###.#",
+	'H': "#....# #....# #....# #....# ###### #....# #....# #....# #....# #....#",
+	'J': "...### ....#. ....#. ....#. ....#. ....#. ....#. #...#. #...#. .###..",
+	'K': "#....# #...#. #..#.. #.#... ##.... ##.... #.#... #..#.. #...#. #....#",
+	'L': "#..... #..... #..... #..... #..... #..... #..... #..... #..... ######",
+	'N': "#....# ##...# ##...# #.#..# #.#..# #..#.# #..#.# #...## #...## #....#",
+	'P': "#####. #....# #....# #....# #####. #..... #..... #..... #..... #.....",
+	'R': "#####. #....# #....# #....# #####. #..#.. #...#. #...#. #....# #....#",
+	'X': "#....# #....# .#..#. .#..#. ..##.. ..##.. .#..#. .#..#. #....# #....#",
+	'Z': "###### .....# .....# ....#. ...#.. ..#... .#.... #..... #..... ######",
+}
+
+var (
+	ocrOnce sync.Once
+	// ocrTables maps bitmap keys (see glyphKey) to runes, by glyph height.
+	ocrTables = map[int]map[string]rune{}
+	// ocrPixl maps the keys of Pixl glyphs, as laid out by TypesetBytes, to
+	// runes.
+	ocrPixl = map[string]rune{}
+)
+
+func ocrInit() {
+	for height, letters := range map[int]map[rune]string{6: ocrLetters6, 10: ocrLetters10} {
+		ocrTables[height] = map[string]rune{}
+		for r, art := range letters {
+			var rows [][]bool
+			for _, line := range strings.Fields(art) {
+				row := make([]bool, len(line))
+				for i, c := range line {
+					row[i] = c == '#'
+				}
+				rows = append(rows, row)
+			}
+			left, right := inkColumns(rows, 0, len(rows[0]))
+			ocrTables[height][glyphKey(rows, left, right)] = r
+		}
+	}
+
+	for r, g := range Glyphs[Pixl] {
+		key := glyphKey(g.Raw, 0, GlyphWidth)
+		// several runes may share a bitmap; pick one consistently
+		if prev, ok := ocrPixl[key]; !ok || r < prev {
+			ocrPixl[key] = r
+		}
+	}
+}
+
+// glyphKey returns a string uniquely identifying the pixels of rows in columns
+// [left, right), ignoring blank rows at the bottom. Missing pixels (in ragged
+// rows) are blank.
+func glyphKey(rows [][]bool, left, right int) string {
+	var lines []string
+	blank := 0
+	for _, row := range rows {
+		sb := &strings.Builder{}
+		lit := false
+		for x := left; x < right; x++ {
+			if x < len(row) && row[x] {
+				sb.WriteByte('#')
+				lit = true
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		lines = append(lines, sb.String())
+		if lit {
+			blank = 0
+		} else {
+			blank++
+		}
+	}
+	return strings.Join(lines[:len(lines)-blank], "\n")
+}
+
+// inkColumns returns the narrowest range of columns [left, right) within
+// [from, to) that contains every lit pixel. If there are none, left == right.
+func inkColumns(rows [][]bool, from, to int) (left, right int) {
+	left, right = to, from
+	for x := from; x < to; x++ {
+		if columnLit(rows, x) {
+			if x < left {
+				left = x
+			}
+			right = x + 1
+		}
+	}
+	if left > right {
+		return from, from
+	}
+	return left, right
+}
+
+func columnLit(rows [][]bool, x int) bool {
+	for _, row := range rows {
+		if x < len(row) && row[x] {
+			return true
+		}
+	}
+	return false
+}
+
+// UnknownGlyph describes a glyph that OCR couldn't recognize.
+type UnknownGlyph struct {
+	// Index is the glyph's position in the recognized text.
+	Index int
+	// Column is the glyph's leftmost column in the input.
+	Column int
+	// Bitmap is the glyph, '#' for lit pixels and '.' for blank ones.
+	Bitmap []string
+}
+
+// OCRError is returned by OCR when some glyphs were not recognized. Text holds
+// what was recognized, with '?' in place of each unknown glyph.
+type OCRError struct {
+	Text    string
+	Unknown []UnknownGlyph
+}
+
+func (e *OCRError) Error() string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "%d unrecognized glyph(s) in %q", len(e.Unknown), e.Text)
+	for _, u := range e.Unknown {
+		fmt.Fprintf(sb, "\nglyph %d at column %d:", u.Index, u.Column)
+		for _, line := range u.Bitmap {
+			sb.WriteString("\n  " + line)
+		}
+	}
+	return sb.String()
+}
+
+// OCR reads the text drawn in rows of pixels. Rows that are 6 or 10 high are
+// read as the AoC block letters, which are separated by blank columns. Anything
+// else, or anything the block letters don't fit, is read as the Pixl font, as
+// drawn by TypesetBytes without scaling: a glyph every GlyphWidth columns, and
+// a line every LineHeight rows. Rows may be ragged; missing pixels are blank.
+//
+// If any glyphs aren't recognized, OCR returns an *OCRError.
+func OCR(rows [][]bool) (string, error) {
+	ocrOnce.Do(ocrInit)
+
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	var r ocrReader
+	if table, ok := ocrTables[len(rows)]; ok {
+		r.readBlocks(table, rows, width)
+		if len(r.unknown) > 0 {
+			// a short line of Pixl might happen to be the same height
+			var pixl ocrReader
+			pixl.readPixl(rows, width)
+			if len(pixl.unknown) == 0 {
+				r = pixl
+			}
+		}
+	} else {
+		r.readPixl(rows, width)
+	}
+
+	if len(r.unknown) > 0 {
+		return string(r.text), &OCRError{Text: string(r.text), Unknown: r.unknown}
+	}
+	return string(r.text), nil
+}
+
+type ocrReader struct {
+	text    []rune
+	unknown []UnknownGlyph
+}
+
+func (o *ocrReader) recognize(table map[string]rune, band [][]bool, left, right int) {
+	key := glyphKey(band, left, right)
+	if r, ok := table[key]; ok {
+		o.text = append(o.text, r)
+		return
+	}
+	o.unknown = append(o.unknown, UnknownGlyph{
+		Index:  len(o.text),
+		Column: left,
+		Bitmap: strings.Split(key, "\n"),
+	})
+	o.text = append(o.text, '?')
+}
+
+// readBlocks reads AoC block letters, each a run of columns with lit pixels.
+func (o *ocrReader) readBlocks(table map[string]rune, rows [][]bool, width int) {
+	for x := 0; x < width; {
+		if !columnLit(rows, x) {
+			x++
+			continue
+		}
+		left := x
+		for x < width && columnLit(rows, x) {
+			x++
+		}
+		o.recognize(table, rows, left, x)
+	}
+}
+
+// readPixl reads Pixl glyphs in fixed-size cells.
+func (o *ocrReader) readPixl(rows [][]bool, width int) {
+	for top := 0; top < len(rows); top += LineHeight {
+		if top > 0 {
+			o.text = append(o.text, '\n')
+		}
+		band := rows[top:]
+		if len(band) > LineHeight {
+			band = band[:LineHeight]
+		}
+		lineStart := len(o.text)
+		for left := 0; left < width; left += GlyphWidth {
+			o.recognize(ocrPixl, band, left, left+GlyphWidth)
+		}
+		// trailing spaces don't make it through TypesetBytes
+		for len(o.text) > lineStart && o.text[len(o.text)-1] == ' ' {
+			o.text = o.text[:len(o.text)-1]
+		}
+	}
+}
+
+// OCRBytes is OCR for rows of bytes, such as from TypesetBytes, where '#' is a
+// lit pixel.
+func OCRBytes(rows [][]byte) (string, error) {
+	bools := make([][]bool, len(rows))
+	for y, row := range rows {
+		bools[y] = make([]bool, len(row))
+		for x, b := range row {
+			bools[y][x] = b == '#'
+		}
+	}
+	return OCR(bools)
+}
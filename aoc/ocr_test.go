@@ -0,0 +1,104 @@
+package aoc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func art(s string) [][]bool {
+	var ret [][]bool
+	for _, line := range strings.Split(strings.Trim(s, "\n"), "\n") {
+		row := make([]bool, len(line))
+		for i, c := range line {
+			row[i] = c == '#'
+		}
+		ret = append(ret, row)
+	}
+	return ret
+}
+
+func TestOCR(t *testing.T) {
+	tests := []struct {
+		name string
+		rows string
+		want string
+	}{
+		{"day10 example", `
+##..##..##..##..##..##..##..##..##..##..
+###...###...###...###...###...###...###.
+####....####....####....####....####....
+#####.....#####.....#####.....#####.....
+######......######......######......####
+#######.......#######.......#######.....
+`, ""},
+		{"4x6", `
+###..#..#.###..####.
+#..#.#..#.#..#.#....
+#..#.####.###..###..
+###..#..#.#..#.#....
+#.#..#..#.#..#.#....
+#..#.#..#.###..####.
+`, "RHBE"},
+		{"6x10", `
+#....#..#####...######
+#....#..#....#.......#
+.#..#...#....#.......#
+.#..#...#....#......#.
+..##....#####......#..
+..##....#.........#...
+.#..#...#........#....
+.#..#...#.......#.....
+#....#..#.......#.....
+#....#..#.......######
+`, "XPZ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OCR(art(tt.rows))
+			if tt.want == "" {
+				var ocrErr *OCRError
+				require.ErrorAs(t, err, &ocrErr)
+				require.NotEmpty(t, ocrErr.Unknown)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOCR_Unknown(t *testing.T) {
+	got, err := OCR(art(`
+.##...#...##.
+#..#.###.#..#
+#..#..#..#..#
+####.....####
+#..#.....#..#
+#..#.....#..#
+`))
+	require.Equal(t, "A?A", got)
+	var ocrErr *OCRError
+	require.ErrorAs(t, err, &ocrErr)
+	require.Equal(t, []UnknownGlyph{{
+		Index:  1,
+		Column: 5,
+		Bitmap: []string{".#.", "###", ".#."},
+	}}, ocrErr.Unknown)
+}
+
+func TestOCR_Pixl(t *testing.T) {
+	for _, line := range []string{
+		"Hello, World!",
+		"The quick brown fox jumps over the lazy dog",
+		"0123456789",
+		"two\nlines",
+	} {
+		t.Run(line, func(t *testing.T) {
+			got, err := OCRBytes(TypesetBytes(line))
+			require.NoError(t, err)
+			require.Equal(t, line, got)
+		})
+	}
+}
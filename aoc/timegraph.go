@@ -0,0 +1,160 @@
+package aoc
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/asymmetricia/aoc22/aoc/mathx"
+	"github.com/asymmetricia/aoc22/coord"
+	"github.com/asymmetricia/aoc22/set"
+)
+
+// TimeNode is a position at a moment in a periodic timeline. T is always in
+// [0, Period), so nodes at the same place and phase are the same node.
+type TimeNode struct {
+	Coord coord.Coord
+	T     int
+}
+
+// Equal compares only the position, so that a Dijkstra search for a TimeNode
+// ends when it reaches the end's position at any time.
+func (n TimeNode) Equal(b TimeNode) bool {
+	return n.Coord == b.Coord
+}
+
+// TimeGraph is the time-expanded graph of a grid with moving obstacles, where
+// the obstacles repeat every Period steps.
+type TimeGraph struct {
+	Period int
+
+	// Obstacles returns the cells that are blocked at time t, for t in [0,
+	// Period). It's called at most once for each t.
+	Obstacles func(t int) set.Set[coord.Coord]
+
+	// Open reports whether c can ever be occupied, e.g. is within the walls.
+	Open func(c coord.Coord) bool
+
+	// Diag allows diagonal moves; Wait allows staying in place for a step.
+	Diag bool
+	Wait bool
+
+	cache []set.Set[coord.Coord]
+}
+
+// ErrBadPeriod is returned for a TimeGraph whose Period isn't positive.
+var ErrBadPeriod = errors.New("time graph period must be positive")
+
+// Validate checks that g can be searched.
+func (g *TimeGraph) Validate() error {
+	switch {
+	case g.Period <= 0:
+		return fmt.Errorf("%w, got %d", ErrBadPeriod, g.Period)
+	case g.Obstacles == nil || g.Open == nil:
+		return errors.New("time graph needs Obstacles and Open")
+	}
+	return nil
+}
+
+// Blocked reports whether c is blocked at time t, which may be any time,
+// even a negative one. It returns ErrBadPeriod if the Period isn't positive.
+func (g *TimeGraph) Blocked(c coord.Coord, t int) (bool, error) {
+	if g.Period <= 0 {
+		return false, fmt.Errorf("%w, got %d", ErrBadPeriod, g.Period)
+	}
+	if len(g.cache) != g.Period {
+		g.cache = make([]set.Set[coord.Coord], g.Period)
+	}
+	t = mathx.Mod(t, g.Period)
+	if g.cache[t] == nil {
+		g.cache[t] = g.Obstacles(t)
+	}
+	return g.cache[t][c], nil
+}
+
+// Neighbors returns the nodes reachable from n in one step; suitable for use
+// with Dijkstra. There are none if g isn't valid.
+func (g *TimeGraph) Neighbors(n TimeNode) []TimeNode {
+	if g.Validate() != nil {
+		return nil
+	}
+	t := mathx.Mod(n.T+1, g.Period)
+	candidates := n.Coord.Neighbors(g.Diag)
+	if g.Wait {
+		candidates = append(candidates, n.Coord)
+	}
+
+	var ret []TimeNode
+	for _, c := range candidates {
+		if !g.Open(c) {
+			continue
+		}
+		if blocked, _ := g.Blocked(c, t); !blocked {
+			ret = append(ret, TimeNode{c, t})
+		}
+	}
+	return ret
+}
+
+// Path finds the shortest path from start, beginning at time t, to end. The
+// path includes the start node; it's nil if end can't be reached. It returns
+// an error if g isn't valid.
+func (g *TimeGraph) Path(start coord.Coord, t int, end coord.Coord) ([]TimeNode, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return Dijkstra(
+		TimeNode{start, mathx.Mod(t, g.Period)},
+		TimeNode{end, 0},
+		g.Neighbors,
+		ConstantCost[TimeNode]), nil
+}
+
+// Route finds the fastest route from start, beginning at time t, that visits
+// each of the waypoints in order, e.g. start→end→start→end. It returns the
+// time at which the last waypoint is reached and the path for each leg, or -1
+// and nil if some leg is impossible.
+//
+// Each leg is found independently. That's optimal as long as waiting at a
+// waypoint is always possible, since then arriving earlier is never worse. It
+// returns an error if g isn't valid.
+func (g *TimeGraph) Route(start coord.Coord, t int, waypoints ...coord.Coord) (int, [][]TimeNode, error) {
+	var legs [][]TimeNode
+	for _, wp := range waypoints {
+		path, err := g.Path(start, t, wp)
+		if err != nil {
+			return -1, nil, err
+		}
+		if path == nil {
+			return -1, nil, nil
+		}
+		legs = append(legs, path)
+		t += len(path) - 1
+		start = wp
+	}
+	return t, legs, nil
+}
+
+// Wrapping returns an obstacle function and its period for obstacles that each
+// move one step per unit of time in a fixed direction, wrapping around within
+// bounds, like day 24's blizzards. The bounds must not be empty.
+func Wrapping(initial map[coord.Coord]coord.Direction, bounds image.Rectangle) (obstacles func(t int) set.Set[coord.Coord], period int, err error) {
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= 0 || h <= 0 {
+		return nil, 0, fmt.Errorf("%w: empty bounds %v", ErrBadPeriod, bounds)
+	}
+	period, err = mathx.LCM(w, h)
+	if err != nil {
+		return nil, 0, err
+	}
+	return func(t int) set.Set[coord.Coord] {
+		ret := set.Set[coord.Coord]{}
+		for c, dir := range initial {
+			d := coord.Coord{}.Move(dir)
+			x := mathx.Mod(c.X-bounds.Min.X+d.X*t, w) + bounds.Min.X
+			y := mathx.Mod(c.Y-bounds.Min.Y+d.Y*t, h) + bounds.Min.Y
+			ret[coord.C(x, y)] = true
+		}
+		return ret
+	}, period, nil
+}
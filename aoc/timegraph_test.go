@@ -0,0 +1,78 @@
+package aoc
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/asymmetricia/aoc22/coord"
+	"github.com/asymmetricia/aoc22/set"
+)
+
+func TestTimeGraph_Route(t *testing.T) {
+	valley := coord.Load([]string{
+		"#.######",
+		"#>>.<^<#",
+		"#.<..<<#",
+		"#>v.><>#",
+		"#<^v^^>#",
+		"######.#",
+	}, true)
+
+	dirs := map[rune]coord.Direction{'>': coord.East, '<': coord.West, '^': coord.North, 'v': coord.South}
+	blizzards := map[coord.Coord]coord.Direction{}
+	for r, d := range dirs {
+		for _, c := range valley.Find(r) {
+			blizzards[c] = d
+		}
+	}
+
+	inner := image.Rect(1, 1, 7, 5)
+	start, end := coord.C(1, 0), coord.C(6, 5)
+	obstacles, period, err := Wrapping(blizzards, inner)
+	require.NoError(t, err)
+	require.Equal(t, 12, period)
+
+	g := &TimeGraph{
+		Period:    period,
+		Obstacles: obstacles,
+		Open: func(c coord.Coord) bool {
+			return c == start || c == end || image.Pt(c.X, c.Y).In(inner)
+		},
+		Wait: true,
+	}
+
+	path, err := g.Path(start, 0, end)
+	require.NoError(t, err)
+	require.Len(t, path, 19)
+	require.Equal(t, TimeNode{end, 18 % period}, path[len(path)-1])
+
+	total, legs, err := g.Route(start, 0, end, start, end)
+	require.NoError(t, err)
+	require.Equal(t, 54, total)
+	require.Len(t, legs, 3)
+	require.Equal(t, []int{19, 24, 14}, []int{len(legs[0]), len(legs[1]), len(legs[2])})
+}
+
+func TestTimeGraph_BadPeriod(t *testing.T) {
+	_, _, err := Wrapping(nil, image.Rect(1, 1, 1, 5))
+	require.True(t, errors.Is(err, ErrBadPeriod))
+
+	g := &TimeGraph{
+		Obstacles: func(int) set.Set[coord.Coord] { return set.Set[coord.Coord]{coord.C(0, 0): true} },
+		Open:      func(coord.Coord) bool { return true },
+	}
+	_, err = g.Blocked(coord.C(0, 0), 3)
+	require.True(t, errors.Is(err, ErrBadPeriod))
+	_, err = g.Path(coord.C(0, 0), 0, coord.C(1, 0))
+	require.True(t, errors.Is(err, ErrBadPeriod))
+	require.Nil(t, g.Neighbors(TimeNode{}))
+
+	// negative times wrap around like any other
+	g.Period = 3
+	blocked, err := g.Blocked(coord.C(0, 0), -7)
+	require.NoError(t, err)
+	require.True(t, blocked)
+}
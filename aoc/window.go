@@ -0,0 +1,197 @@
+package aoc
+
+import (
+	"golang.org/x/exp/constraints"
+
+	"github.com/asymmetricia/aoc22/set"
+)
+
+// Number is any integer or floating-point type.
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Reducer keeps a running summary of a SlidingWindow, updated as each value
+// enters and leaves it.
+type Reducer[T any] interface {
+	Enter(v T)
+	Leave(v T)
+}
+
+// SlidingWindow steps a window of fixed size along a slice, one value at a
+// time, and keeps track of what's in it: each step only accounts for the value
+// that enters and the value that leaves, so a full pass costs O(n) however
+// large the window. Use it like bufio.Scanner:
+//
+//	w := NewSlidingWindow(data, 4)
+//	for w.Next() {
+//		if w.AllDistinct() { ... }
+//	}
+type SlidingWindow[T Number] struct {
+	data       []T
+	size       int
+	start, end int
+	started    bool
+
+	distinct *set.WindowCounter[T]
+	sum      T
+	// minq and maxq are the indices of the values in the window that might yet
+	// be its minimum or maximum, oldest first, so their values only increase
+	// or decrease, respectively.
+	minq, maxq []int
+	reducers   []Reducer[T]
+}
+
+// NewSlidingWindow returns a window of size values over data, positioned before
+// the first full window; call Next to move to it. size must be positive.
+func NewSlidingWindow[T Number](data []T, size int) *SlidingWindow[T] {
+	if size < 1 {
+		panic("aoc.NewSlidingWindow: size must be positive")
+	}
+	return &SlidingWindow[T]{
+		data:     data,
+		size:     size,
+		distinct: set.NewWindowCounter[T](size),
+	}
+}
+
+// AddReducer adds r to the window. It's told about every value already in the
+// window, and every value that enters or leaves it from now on.
+func (w *SlidingWindow[T]) AddReducer(r Reducer[T]) {
+	for _, v := range w.Window() {
+		r.Enter(v)
+	}
+	w.reducers = append(w.reducers, r)
+}
+
+// Next moves to the next full window, and reports whether there is one.
+func (w *SlidingWindow[T]) Next() bool {
+	if !w.started {
+		w.started = true
+		if len(w.data) < w.size {
+			return false
+		}
+		for w.end < w.size {
+			w.enter()
+		}
+		return true
+	}
+	if w.end >= len(w.data) {
+		return false
+	}
+	w.leave()
+	w.enter()
+	return true
+}
+
+func (w *SlidingWindow[T]) enter() {
+	i := w.end
+	v := w.data[i]
+	w.end++
+
+	// the counter evicts the value that just left by itself
+	w.distinct.Push(v)
+	w.sum += v
+	for len(w.minq) > 0 && w.data[w.minq[len(w.minq)-1]] >= v {
+		w.minq = w.minq[:len(w.minq)-1]
+	}
+	w.minq = append(w.minq, i)
+	for len(w.maxq) > 0 && w.data[w.maxq[len(w.maxq)-1]] <= v {
+		w.maxq = w.maxq[:len(w.maxq)-1]
+	}
+	w.maxq = append(w.maxq, i)
+	for _, r := range w.reducers {
+		r.Enter(v)
+	}
+}
+
+func (w *SlidingWindow[T]) leave() {
+	i := w.start
+	v := w.data[i]
+	w.start++
+
+	w.sum -= v
+	if w.minq[0] == i {
+		w.minq = w.minq[1:]
+	}
+	if w.maxq[0] == i {
+		w.maxq = w.maxq[1:]
+	}
+	for _, r := range w.reducers {
+		r.Leave(v)
+	}
+}
+
+// Start returns the index of the first value in the window.
+func (w *SlidingWindow[T]) Start() int {
+	return w.start
+}
+
+// End returns the index just past the last value in the window.
+func (w *SlidingWindow[T]) End() int {
+	return w.end
+}
+
+// Window returns the values in the window. It shares storage with the data.
+func (w *SlidingWindow[T]) Window() []T {
+	return w.data[w.start:w.end]
+}
+
+// AllDistinct reports whether the window is full and no two values in it are
+// equal.
+func (w *SlidingWindow[T]) AllDistinct() bool {
+	return w.distinct.AllDistinct()
+}
+
+// Distinct returns the number of different values in the window.
+func (w *SlidingWindow[T]) Distinct() int {
+	return w.distinct.Distinct()
+}
+
+// Count returns how many times v appears in the window.
+func (w *SlidingWindow[T]) Count(v T) int {
+	return w.distinct.Counter.Count(v)
+}
+
+// Sum returns the sum of the window. For floating-point values, it's kept as a
+// running total, so rounding errors can build up over a long pass.
+func (w *SlidingWindow[T]) Sum() T {
+	return w.sum
+}
+
+// Min returns the smallest value in the window, or the zero value before the
+// first call to Next.
+func (w *SlidingWindow[T]) Min() T {
+	var ret T
+	if len(w.minq) > 0 {
+		ret = w.data[w.minq[0]]
+	}
+	return ret
+}
+
+// Max returns the largest value in the window, or the zero value before the
+// first call to Next.
+func (w *SlidingWindow[T]) Max() T {
+	var ret T
+	if len(w.maxq) > 0 {
+		ret = w.data[w.maxq[0]]
+	}
+	return ret
+}
+
+// FirstDistinctRun returns the index just past the first run of k values in data
+// that are all different, like the start-of-packet markers of AoC 2022 day 6,
+// or -1 if there's no such run.
+func FirstDistinctRun[T comparable](data []T, k int) int {
+	if k < 1 {
+		panic("aoc.FirstDistinctRun: k must be positive")
+	}
+	w := set.NewWindowCounter[T](k)
+	for i, v := range data {
+		w.Push(v)
+		if w.AllDistinct() {
+			return i + 1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,64 @@
+package aoc
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFirstDistinctRun covers the edges; set.TestWindowCounter has the day 6
+// examples.
+func TestFirstDistinctRun(t *testing.T) {
+	require.Equal(t, 7, FirstDistinctRun([]byte("mjqjpqmgbljsphdztnvjfqwrcgsmlb"), 4))
+	require.Equal(t, -1, FirstDistinctRun([]byte("aaaa"), 2))
+	require.Equal(t, -1, FirstDistinctRun([]byte("ab"), 3))
+	require.Equal(t, 1, FirstDistinctRun([]string{"x"}, 1))
+}
+
+type xorReducer struct{ x int }
+
+func (r *xorReducer) Enter(v int) { r.x ^= v }
+func (r *xorReducer) Leave(v int) { r.x ^= v }
+
+// TestSlidingWindow checks every statistic against computing it from scratch.
+func TestSlidingWindow(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	data := make([]int, 200)
+	for i := range data {
+		data[i] = rng.Intn(30) - 10
+	}
+
+	for _, size := range []int{1, 3, 10, 200} {
+		w := NewSlidingWindow(data, size)
+		xor := &xorReducer{}
+		w.AddReducer(xor)
+		windows := 0
+		for w.Next() {
+			require.Equal(t, windows, w.Start())
+			require.Equal(t, windows+size, w.End())
+			window := data[windows : windows+size]
+			windows++
+
+			seen := map[int]bool{}
+			sum, x := 0, 0
+			for _, v := range window {
+				seen[v] = true
+				sum += v
+				x ^= v
+			}
+			require.Equal(t, window, w.Window())
+			require.Equal(t, len(seen), w.Distinct())
+			require.Equal(t, len(seen) == size, w.AllDistinct())
+			require.Equal(t, sum, w.Sum())
+			require.Equal(t, Min(window...), w.Min())
+			require.Equal(t, Max(window...), w.Max())
+			require.Equal(t, x, xor.x)
+		}
+		require.Equal(t, len(data)-size+1, windows, size)
+	}
+
+	w := NewSlidingWindow([]float64{1, 2}, 3)
+	require.False(t, w.Next())
+	require.Zero(t, w.Max())
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/asymmetricia/aoc22/aoc"
 )
 
 func TestTextBox_On(t *testing.T) {
@@ -54,3 +56,16 @@ func TestTextBox_On(t *testing.T) {
 		})
 	}
 }
+
+func TestHeatmap(t *testing.T) {
+	cnv := Heatmap([][]int{{0, 5}, {10, 5}}, nil)
+	assert.Equal(t, '#', cnv.Pix[0][0].Value)
+	assert.Equal(t, aoc.TolScale(0, 10, 0), cnv.Pix[0][0].Color)
+	assert.Equal(t, aoc.TolScale(0, 10, 10), cnv.Pix[1][0].Color)
+	assert.Equal(t, cnv.Pix[0][1].Color, cnv.Pix[1][1].Color)
+
+	// a flat grid still gets a color
+	flat := Heatmap([][]int{{3, 3}}, func(x, y, v int) rune { return rune('0' + v) })
+	assert.Equal(t, '3', flat.Pix[0][1].Value)
+	assert.NotNil(t, flat.Pix[0][1].Color)
+}
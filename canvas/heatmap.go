@@ -0,0 +1,38 @@
+package canvas
+
+import "github.com/asymmetricia/aoc22/aoc"
+
+// Heatmap draws a grid of values, indexed [y][x], as a canvas with one cell per
+// value, colored by aoc.TolScale from the lowest value to the highest. Each
+// cell shows its own glyph, from glyph; a nil glyph means '#'.
+func Heatmap(values [][]int, glyph func(x, y, v int) rune) *Canvas {
+	lo, hi := 0, 0
+	first := true
+	for _, row := range values {
+		for _, v := range row {
+			if first || v < lo {
+				lo = v
+			}
+			if first || v > hi {
+				hi = v
+			}
+			first = false
+		}
+	}
+
+	ret := &Canvas{}
+	for y, row := range values {
+		for x, v := range row {
+			color := aoc.TolScale(0, 1, 0)
+			if hi > lo {
+				color = aoc.TolScale(lo, hi, v)
+			}
+			r := '#'
+			if glyph != nil {
+				r = glyph(x, y, v)
+			}
+			ret.Set(x, y, Cell{Color: color, Value: r})
+		}
+	}
+	return ret
+}
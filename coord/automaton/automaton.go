@@ -0,0 +1,448 @@
+// Package automaton runs step-wise, synchronous simulations on coord.Worlds:
+// classic cellular automata (each cell's next value computed from its
+// neighborhood) and agent-style simulations where occupied cells propose moves
+// that are resolved all at once.
+package automaton
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+
+	"github.com/asymmetricia/aoc22/coord"
+)
+
+// Neighborhood is a list of offsets, relative to a cell, that make up that
+// cell's neighbors.
+type Neighborhood []coord.Coord
+
+// VonNeumann is the four orthogonal neighbors, clockwise from north.
+var VonNeumann = Neighborhood{
+	coord.C(0, -1), coord.C(1, 0), coord.C(0, 1), coord.C(-1, 0),
+}
+
+// Moore is all eight neighbors, clockwise from north; i.e., in the same order
+// as coord.Directions.
+var Moore = Neighborhood{
+	coord.C(0, -1), coord.C(1, -1), coord.C(1, 0), coord.C(1, 1),
+	coord.C(0, 1), coord.C(-1, 1), coord.C(-1, 0), coord.C(-1, -1),
+}
+
+// Cell is what a Rule or Propose function sees of a single cell.
+type Cell struct {
+	Coord coord.Coord
+	Value rune
+	// Neighbors holds the values of the cells in the Automaton's Neighborhood,
+	// in the same order, as returned by World.At.
+	Neighbors []rune
+	// Generation is the number of steps completed so far.
+	Generation int
+	World      coord.World
+}
+
+// Count returns the number of neighbors with the value r.
+func (c Cell) Count(r rune) int {
+	n := 0
+	for _, v := range c.Neighbors {
+		if v == r {
+			n++
+		}
+	}
+	return n
+}
+
+// Result describes how a call to Run ended.
+type Result struct {
+	// Generation is the automaton's generation when Run returned.
+	Generation int
+
+	// Stable is true if the last step changed nothing.
+	Stable bool
+
+	// If a cycle was detected, CycleStart is the first generation of the cycle
+	// and CycleLength is the number of steps it takes to repeat. CycleLength is
+	// zero if no cycle was detected.
+	CycleStart, CycleLength int
+}
+
+// Automaton is a synchronous simulation on a World. Rule and Propose are both
+// optional, but at least one should be set; if both are set, Rule is applied
+// first and moves are proposed against its result.
+type Automaton struct {
+	// World is the current state. The automaton swaps it with an internal
+	// buffer each step, so hold on to the Automaton, not the World.
+	World coord.World
+
+	// Neighborhood defaults to Moore.
+	Neighborhood Neighborhood
+
+	// Background is the value of an empty cell, in addition to unset cells. For
+	// sparse worlds, cells set to Background are removed.
+	Background rune
+
+	// Rule, if set, computes the next value of every cell. For sparse worlds it
+	// is called for every occupied cell and every neighbor of one; for dense
+	// worlds, for every cell in the World's Rect.
+	Rule func(Cell) rune
+
+	// Propose, if set, is called for every occupied cell and may return a
+	// destination the cell would like to move to. A dense world can't hold
+	// negative coordinates, so proposals to move there are dropped.
+	Propose func(Cell) (to coord.Coord, ok bool)
+
+	// Resolve decides which of the cells proposing to move to the same
+	// destination, if any, gets to. By default, a move only happens if it's the
+	// only one proposed for its destination.
+	Resolve func(to coord.Coord, from []coord.Coord) (winner coord.Coord, ok bool)
+
+	// Callbacks are called after each step, e.g. to capture frames.
+	Callbacks []func(a *Automaton)
+
+	// DetectCycles enables cycle detection in Run. It hashes the world after
+	// every step, and confirms a repeated hash by comparing the occupied cells
+	// with those it saw before.
+	DetectCycles bool
+
+	// CycleKey, if set, returns the state a Rule or Propose function keeps
+	// outside the World, which must match too for a world to count as
+	// repeated; e.g., day 23's elves consider directions in an order that
+	// rotates every generation, so their key is Generation%4. Keys are compared
+	// with ==.
+	CycleKey func(a *Automaton) any
+
+	Generation int
+
+	back coord.World
+	seen map[uint64][]snapshot
+}
+
+// snapshot is what cycle detection remembers of a generation.
+type snapshot struct {
+	generation int
+	key        any
+	cells      []coord.Coord
+	values     []rune
+}
+
+func (s snapshot) equal(o snapshot) bool {
+	if s.key != o.key || len(s.cells) != len(o.cells) {
+		return false
+	}
+	for i := range s.cells {
+		if s.cells[i] != o.cells[i] || s.values[i] != o.values[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Step advances the automaton by one generation, returning true if any cell
+// changed.
+func (a *Automaton) Step() (changed bool) {
+	if a.Neighborhood == nil {
+		a.Neighborhood = Moore
+	}
+
+	if a.Rule != nil {
+		changed = a.applyRule() || changed
+	}
+	if a.Propose != nil {
+		changed = a.applyMoves() || changed
+	}
+
+	a.Generation++
+	for _, cb := range a.Callbacks {
+		cb(a)
+	}
+	return changed
+}
+
+// Run steps the automaton until it's stable, a cycle is detected (if
+// DetectCycles is set), or max steps have been taken. If max is negative, there
+// is no limit.
+func (a *Automaton) Run(max int) Result {
+	if a.DetectCycles && a.seen == nil {
+		a.seen = map[uint64][]snapshot{}
+		a.remember()
+	}
+
+	for i := 0; max < 0 || i < max; i++ {
+		if !a.Step() {
+			return Result{Generation: a.Generation, Stable: true}
+		}
+		if !a.DetectCycles {
+			continue
+		}
+		if start, ok := a.remember(); ok {
+			return Result{
+				Generation:  a.Generation,
+				CycleStart:  start,
+				CycleLength: a.Generation - start,
+			}
+		}
+	}
+	return Result{Generation: a.Generation}
+}
+
+// remember records the current generation for cycle detection, unless it
+// repeats an earlier one, in which case it returns that generation.
+func (a *Automaton) remember() (start int, repeated bool) {
+	snap := snapshot{generation: a.Generation, cells: a.occupied()}
+	if a.CycleKey != nil {
+		snap.key = a.CycleKey(a)
+	}
+	snap.values = make([]rune, len(snap.cells))
+	for i, c := range snap.cells {
+		snap.values[i] = a.World.At(c)
+	}
+
+	h := snap.hash()
+	for _, prev := range a.seen[h] {
+		if prev.equal(snap) {
+			return prev.generation, true
+		}
+	}
+	a.seen[h] = append(a.seen[h], snap)
+	return 0, false
+}
+
+// Occupied reports whether r is the value of an occupied cell.
+func (a *Automaton) Occupied(r rune) bool {
+	return r > 0 && r != a.Background
+}
+
+func (a *Automaton) cell(c coord.Coord) Cell {
+	ret := Cell{
+		Coord:      c,
+		Value:      a.World.At(c),
+		Neighbors:  make([]rune, len(a.Neighborhood)),
+		Generation: a.Generation,
+		World:      a.World,
+	}
+	for i, offset := range a.Neighborhood {
+		ret.Neighbors[i] = a.World.At(c.Plus(offset))
+	}
+	return ret
+}
+
+// occupied returns the occupied cells of the world, sorted so that proposals
+// are made in a stable order.
+func (a *Automaton) occupied() []coord.Coord {
+	var ret []coord.Coord
+	a.World.Each(func(c coord.Coord) bool {
+		if a.Occupied(a.World.At(c)) {
+			ret = append(ret, c)
+		}
+		return false
+	})
+	sortCoords(ret)
+	return ret
+}
+
+func (a *Automaton) applyRule() bool {
+	var domain []coord.Coord
+	if _, dense := a.World.(*coord.DenseWorld); dense {
+		minX, minY, maxX, maxY := a.World.Rect()
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				domain = append(domain, coord.C(x, y))
+			}
+		}
+	} else {
+		seen := map[coord.Coord]bool{}
+		for _, c := range a.occupied() {
+			seen[c] = true
+			for _, offset := range a.Neighborhood {
+				seen[c.Plus(offset)] = true
+			}
+		}
+		for c := range seen {
+			domain = append(domain, c)
+		}
+	}
+
+	a.back = reset(a.back, a.World)
+	changed := false
+	for _, c := range domain {
+		if !a.holds(c) {
+			continue
+		}
+		cell := a.cell(c)
+		next := a.Rule(cell)
+		if next != cell.Value && (a.Occupied(next) || a.Occupied(cell.Value)) {
+			changed = true
+		}
+		a.set(a.back, c, next)
+	}
+	a.World, a.back = a.back, a.World
+	return changed
+}
+
+func (a *Automaton) applyMoves() bool {
+	proposals := map[coord.Coord][]coord.Coord{}
+	var destinations []coord.Coord
+	for _, c := range a.occupied() {
+		to, ok := a.Propose(a.cell(c))
+		if !ok || to == c || !a.holds(to) {
+			continue
+		}
+		if _, ok := proposals[to]; !ok {
+			destinations = append(destinations, to)
+		}
+		proposals[to] = append(proposals[to], c)
+	}
+
+	resolve := a.Resolve
+	if resolve == nil {
+		resolve = Unique
+	}
+
+	type move struct{ from, to coord.Coord }
+	var moves []move
+	for _, to := range destinations {
+		if from, ok := resolve(to, proposals[to]); ok {
+			moves = append(moves, move{from, to})
+		}
+	}
+	if len(moves) == 0 {
+		return false
+	}
+
+	a.back = a.copyInto(a.back, a.World)
+	for _, m := range moves {
+		a.set(a.back, m.from, a.Background)
+	}
+	for _, m := range moves {
+		a.set(a.back, m.to, a.World.At(m.from))
+	}
+	a.World, a.back = a.back, a.World
+	return true
+}
+
+// holds reports whether the world can hold a cell at c: anywhere for sparse
+// worlds, but only at non-negative coordinates for dense ones.
+func (a *Automaton) holds(c coord.Coord) bool {
+	if _, dense := a.World.(*coord.DenseWorld); dense {
+		return c.X >= 0 && c.Y >= 0
+	}
+	return true
+}
+
+// set stores r at c, removing it instead if w is sparse and r is empty.
+func (a *Automaton) set(w coord.World, c coord.Coord, r rune) {
+	if !a.Occupied(r) {
+		if _, sparse := w.(*coord.SparseWorld); sparse {
+			r = 0
+		} else if r < 0 {
+			r = a.Background
+		}
+	}
+	w.Set(c, r)
+}
+
+// hash returns a hash of the occupied cells, so that worlds that differ only
+// in how empty cells are represented hash the same. The key isn't hashed; it
+// only has to be comparable, so it's checked by equal.
+func (s snapshot) hash() uint64 {
+	h := fnv.New64a()
+	var buf [24]byte
+	for i, c := range s.cells {
+		binary.LittleEndian.PutUint64(buf[0:], uint64(c.X))
+		binary.LittleEndian.PutUint64(buf[8:], uint64(c.Y))
+		binary.LittleEndian.PutUint64(buf[16:], uint64(s.values[i]))
+		_, _ = h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// Unique is the default Resolve function: a move happens only if no other cell
+// proposed the same destination.
+func Unique(_ coord.Coord, from []coord.Coord) (coord.Coord, bool) {
+	if len(from) != 1 {
+		return coord.Coord{}, false
+	}
+	return from[0], true
+}
+
+// First is a Resolve function that lets the first proposer (in reading order)
+// move.
+func First(_ coord.Coord, from []coord.Coord) (coord.Coord, bool) {
+	return from[0], true
+}
+
+// reset returns an empty world of the same kind and size as like, reusing buf
+// if possible. Other kinds of World than SparseWorld and DenseWorld are copied
+// from like and then cleared, by setting every cell to 0.
+func reset(buf, like coord.World) coord.World {
+	switch l := like.(type) {
+	case *coord.SparseWorld:
+		b, ok := buf.(*coord.SparseWorld)
+		if !ok {
+			return &coord.SparseWorld{}
+		}
+		for c := range *b {
+			delete(*b, c)
+		}
+		return b
+	case *coord.DenseWorld:
+		b, ok := buf.(*coord.DenseWorld)
+		if !ok {
+			b = &coord.DenseWorld{}
+		}
+		if cap(*b) < len(*l) {
+			*b = make(coord.DenseWorld, len(*l))
+		}
+		*b = (*b)[:len(*l)]
+		for y, row := range *l {
+			if cap((*b)[y]) < len(row) {
+				(*b)[y] = make([]rune, len(row))
+			}
+			(*b)[y] = (*b)[y][:len(row)]
+			for x := range (*b)[y] {
+				(*b)[y][x] = 0
+			}
+		}
+		return b
+	}
+	ret := like.Copy()
+	var cells []coord.Coord
+	ret.Each(func(c coord.Coord) bool {
+		cells = append(cells, c)
+		return false
+	})
+	for _, c := range cells {
+		ret.Set(c, 0)
+	}
+	return ret
+}
+
+// copyInto returns a copy of src, reusing buf if possible. Empty cells are
+// dropped when copying sparse worlds.
+func (a *Automaton) copyInto(buf, src coord.World) coord.World {
+	switch s := src.(type) {
+	case *coord.SparseWorld:
+		b := reset(buf, src).(*coord.SparseWorld)
+		for c, r := range *s {
+			if a.Occupied(r) {
+				(*b)[c] = r
+			}
+		}
+		return b
+	case *coord.DenseWorld:
+		b := reset(buf, src).(*coord.DenseWorld)
+		for y, row := range *s {
+			copy((*b)[y], row)
+		}
+		return b
+	}
+	return src.Copy()
+}
+
+func sortCoords(cs []coord.Coord) {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Y != cs[j].Y {
+			return cs[i].Y < cs[j].Y
+		}
+		return cs[i].X < cs[j].X
+	})
+}
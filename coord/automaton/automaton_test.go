@@ -0,0 +1,158 @@
+package automaton
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/asymmetricia/aoc22/coord"
+)
+
+func life(c Cell) rune {
+	n := c.Count('#')
+	if n == 3 || n == 2 && c.Value == '#' {
+		return '#'
+	}
+	return '.'
+}
+
+func TestAutomaton_Life(t *testing.T) {
+	for _, dense := range []bool{true, false} {
+		name := map[bool]string{true: "dense", false: "sparse"}[dense]
+		t.Run(name, func(t *testing.T) {
+			a := &Automaton{
+				World: coord.Load([]string{
+					".....",
+					"..#..",
+					"..#..",
+					"..#..",
+					".....",
+				}, dense),
+				Background:   '.',
+				Rule:         life,
+				DetectCycles: true,
+			}
+
+			frames := 0
+			a.Callbacks = append(a.Callbacks, func(*Automaton) { frames++ })
+
+			res := a.Run(10)
+			require.Equal(t, Result{Generation: 2, CycleStart: 0, CycleLength: 2}, res)
+			require.Equal(t, 2, frames)
+			require.ElementsMatch(t, []coord.Coord{coord.C(2, 1), coord.C(2, 2), coord.C(2, 3)}, a.World.Find('#'))
+
+			a.Step()
+			require.ElementsMatch(t, []coord.Coord{coord.C(1, 2), coord.C(2, 2), coord.C(3, 2)}, a.World.Find('#'))
+		})
+	}
+
+	t.Run("still life", func(t *testing.T) {
+		a := &Automaton{
+			World:      coord.Load([]string{"##", "##"}, false),
+			Background: '.',
+			Rule:       life,
+		}
+		require.Equal(t, Result{Generation: 1, Stable: true}, a.Run(-1))
+		require.Len(t, *a.World.(*coord.SparseWorld), 4)
+	})
+}
+
+// elves implements AoC 2022 day 23.
+func elves(c Cell) (coord.Coord, bool) {
+	if c.Count('#') == 0 {
+		return coord.Coord{}, false
+	}
+
+	// Moore neighborhood is in coord.Directions order, so neighbor i is in
+	// direction i
+	considerations := [][]coord.Direction{
+		{coord.North, coord.NorthEast, coord.NorthWest},
+		{coord.South, coord.SouthEast, coord.SouthWest},
+		{coord.West, coord.NorthWest, coord.SouthWest},
+		{coord.East, coord.NorthEast, coord.SouthEast},
+	}
+consider:
+	for i := range considerations {
+		dirs := considerations[(i+c.Generation)%len(considerations)]
+		for _, dir := range dirs {
+			if c.Neighbors[dir] == '#' {
+				continue consider
+			}
+		}
+		return c.Coord.Move(dirs[0]), true
+	}
+	return coord.Coord{}, false
+}
+
+func TestAutomaton_Elves(t *testing.T) {
+	a := &Automaton{
+		World: coord.Load([]string{
+			".....",
+			"..##.",
+			"..#..",
+			".....",
+			"..##.",
+			".....",
+		}, false),
+		Background: '.',
+		Propose:    elves,
+	}
+
+	require.Equal(t, Result{Generation: 3}, a.Run(3))
+	require.Empty(t, coord.Diff(coord.Load([]string{
+		"  #  ",
+		"    #",
+		"#    ",
+		"    #",
+		"     ",
+		"  #  ",
+	}, false), a.World))
+
+	require.Equal(t, Result{Generation: 4, Stable: true}, a.Run(-1))
+}
+
+// TestAutomaton_CycleKey checks that a world repeating isn't a cycle if the
+// rules have moved on: this cell steps east, west, east, and then repeats, so it
+// drifts east without ever cycling.
+func TestAutomaton_CycleKey(t *testing.T) {
+	drift := func(c Cell) (coord.Coord, bool) {
+		if c.Generation%3 == 1 {
+			return c.Coord.Move(coord.West), true
+		}
+		return c.Coord.Move(coord.East), true
+	}
+
+	a := &Automaton{World: &coord.SparseWorld{coord.C(0, 0): '#'}, Propose: drift, DetectCycles: true}
+	require.Equal(t, Result{Generation: 2, CycleStart: 0, CycleLength: 2}, a.Run(10))
+
+	a = &Automaton{
+		World:        &coord.SparseWorld{coord.C(0, 0): '#'},
+		Propose:      drift,
+		DetectCycles: true,
+		CycleKey:     func(a *Automaton) any { return a.Generation % 3 },
+	}
+	require.Equal(t, Result{Generation: 10}, a.Run(10))
+	require.Equal(t, []coord.Coord{coord.C(4, 0)}, a.World.Find('#'))
+}
+
+// TestAutomaton_DenseEdge checks that moves off the top or left of a dense
+// world are dropped rather than panicking, while a sparse world allows them.
+func TestAutomaton_DenseEdge(t *testing.T) {
+	northwest := func(c Cell) (coord.Coord, bool) {
+		return c.Coord.Move(coord.NorthWest), true
+	}
+	for _, dense := range []bool{true, false} {
+		a := &Automaton{
+			World:      coord.Load([]string{"#..", "...", "..#"}, dense),
+			Background: '.',
+			Propose:    northwest,
+		}
+		require.NotPanics(t, func() { a.Step() })
+		want := []coord.Coord{coord.C(-1, -1), coord.C(1, 1)}
+		if dense {
+			// the cell in the corner has nowhere to go
+			want = []coord.Coord{coord.C(0, 0), coord.C(1, 1)}
+		}
+		require.ElementsMatch(t, want, a.World.Find('#'), "dense %v", dense)
+	}
+}
@@ -0,0 +1,31 @@
+package coord
+
+import "github.com/asymmetricia/aoc22/set"
+
+// Components groups the cells of w for which keep returns true into connected
+// regions, where cells are connected if they're neighbors (including
+// diagonals, if diag is true) and both kept.
+func Components(w World, diag bool, keep func(c Coord, r rune) bool) *set.DisjointSet[Coord] {
+	ret := &set.DisjointSet[Coord]{}
+	minX, minY, maxX, maxY := w.Rect()
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			c := C(x, y)
+			if !keep(c, w.At(c)) {
+				continue
+			}
+			ret.Add(c)
+			// only look back at neighbors already visited, since the rest will
+			// look back at us
+			for _, n := range []Coord{c.West(), c.NorthWest(), c.North(), c.NorthEast()} {
+				if !diag && n.X != c.X && n.Y != c.Y {
+					continue
+				}
+				if n.X >= minX && n.X <= maxX && n.Y >= minY && keep(n, w.At(n)) {
+					ret.Union(c, n)
+				}
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,28 @@
+package coord
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponents(t *testing.T) {
+	w := Load([]string{
+		"##..#",
+		"#..#.",
+		"...#.",
+	}, true)
+	rock := func(_ Coord, r rune) bool { return r == '#' }
+
+	require.Equal(t, [][]Coord{
+		{C(0, 0), C(1, 0), C(0, 1)},
+		{C(4, 0)},
+		{C(3, 1), C(3, 2)},
+	}, Components(w, false, rock).Groups())
+
+	require.Equal(t, 2, Components(w, true, rock).Count())
+
+	open := Components(w, false, func(_ Coord, r rune) bool { return r == '.' })
+	require.Equal(t, 2, open.Count())
+	require.True(t, open.Same(C(2, 0), C(0, 2)))
+}
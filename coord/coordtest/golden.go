@@ -0,0 +1,53 @@
+// Package coordtest provides test helpers for code that produces coord.Worlds.
+package coordtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asymmetricia/aoc22/coord"
+)
+
+// UpdateEnv is the environment variable that, when set to a non-empty value,
+// makes Golden rewrite its fixtures instead of comparing against them.
+const UpdateEnv = "COORDTEST_UPDATE"
+
+// Golden compares got against the world stored in the text fixture at path
+// (see coord.MarshalWorld), failing t with a cell-by-cell diff if they differ.
+// If UpdateEnv is set, the fixture is (re)written from got instead.
+func Golden(t testing.TB, got coord.World, path string, legend ...map[rune]string) {
+	t.Helper()
+
+	if os.Getenv(UpdateEnv) != "" {
+		h := coord.TextHeader{Default: coord.UnusedRune(got)}
+		if len(legend) > 0 {
+			h.Legend = legend[0]
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		text, err := coord.MarshalWorld(got, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, text, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	text, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden world (set %s=1 to create it): %v", UpdateEnv, err)
+	}
+
+	want := &coord.SparseWorld{}
+	if _, err := coord.UnmarshalWorld(want, text); err != nil {
+		t.Fatalf("parsing golden world %s: %v", path, err)
+	}
+
+	if diff := coord.Diff(want, got); diff != "" {
+		t.Errorf("world does not match %s; %s", path, diff)
+	}
+}
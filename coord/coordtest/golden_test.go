@@ -0,0 +1,17 @@
+package coordtest
+
+import (
+	"testing"
+
+	"github.com/asymmetricia/aoc22/coord"
+)
+
+func TestGolden(t *testing.T) {
+	w := coord.SparseWorld{
+		coord.C(-2, -1): '#',
+		coord.C(1, -1):  'o',
+		coord.C(-1, 0):  '#',
+		coord.C(0, 0):   '#',
+	}
+	Golden(t, w, "testdata/negative.txt")
+}
@@ -0,0 +1,217 @@
+package coord
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const chunkBits = 4
+const chunkSize = 1 << chunkBits
+
+// owner identifies which CowWorld may modify a map or chunk in place. It must
+// not be zero-sized, so that distinct owners have distinct addresses.
+type owner struct{ _ byte }
+
+type chunk struct {
+	owner *owner
+	cells [chunkSize * chunkSize]rune
+	count int
+}
+
+type chunkMap struct {
+	owner *owner
+	m     map[Coord]*chunk
+}
+
+// CowWorld is a sparse, copy-on-write World: Copy is O(1), and the copies share
+// storage until they're modified. Cells are stored in square chunks, and Set
+// only clones the chunk it touches (and, once after each Copy, the index of
+// chunks). That makes it cheap to keep every step of a simulation or to branch a
+// backtracking search. Like SparseWorld, At returns -1 for unset cells.
+//
+// The zero value is an empty world ready to use.
+type CowWorld struct {
+	chunks *chunkMap
+	owner  *owner
+}
+
+var _ World = (*CowWorld)(nil)
+
+func chunkOf(c Coord) (key Coord, idx int) {
+	key = C(c.X>>chunkBits, c.Y>>chunkBits)
+	return key, (c.Y&(chunkSize-1))<<chunkBits | c.X&(chunkSize-1)
+}
+
+func (w *CowWorld) At(c Coord) rune {
+	if w.chunks == nil {
+		return -1
+	}
+	key, idx := chunkOf(c)
+	ch, ok := w.chunks.m[key]
+	if !ok || ch.cells[idx] == 0 {
+		return -1
+	}
+	return ch.cells[idx]
+}
+
+// Set sets c to r; setting a cell to 0 unsets it.
+func (w *CowWorld) Set(c Coord, r rune) {
+	if w.owner == nil {
+		w.owner = &owner{}
+	}
+	if w.chunks == nil {
+		w.chunks = &chunkMap{owner: w.owner, m: map[Coord]*chunk{}}
+	}
+
+	key, idx := chunkOf(c)
+	ch, ok := w.chunks.m[key]
+	if !ok && r == 0 {
+		return
+	}
+	if ok && ch.cells[idx] == r {
+		return
+	}
+
+	if w.chunks.owner != w.owner {
+		m := make(map[Coord]*chunk, len(w.chunks.m))
+		for k, v := range w.chunks.m {
+			m[k] = v
+		}
+		w.chunks = &chunkMap{owner: w.owner, m: m}
+	}
+
+	switch {
+	case !ok:
+		ch = &chunk{owner: w.owner}
+		w.chunks.m[key] = ch
+	case ch.owner != w.owner:
+		clone := *ch
+		clone.owner = w.owner
+		ch = &clone
+		w.chunks.m[key] = ch
+	}
+
+	switch {
+	case ch.cells[idx] == 0:
+		ch.count++
+	case r == 0:
+		ch.count--
+	}
+	ch.cells[idx] = r
+
+	if ch.count == 0 {
+		delete(w.chunks.m, key)
+	}
+}
+
+// Copy returns a copy of w in O(1). Afterwards, neither w nor the copy owns the
+// shared storage, so the first write to each chunk in either will clone it.
+func (w *CowWorld) Copy() World {
+	w.owner = &owner{}
+	return &CowWorld{chunks: w.chunks, owner: &owner{}}
+}
+
+func (w *CowWorld) Each(f func(Coord) (stop bool)) {
+	if w.chunks == nil {
+		return
+	}
+	for key, ch := range w.chunks.m {
+		for idx, r := range ch.cells {
+			if r == 0 {
+				continue
+			}
+			c := C(key.X<<chunkBits|idx&(chunkSize-1), key.Y<<chunkBits|idx>>chunkBits)
+			if f(c) {
+				return
+			}
+		}
+	}
+}
+
+func (w *CowWorld) Find(r rune) []Coord {
+	var ret []Coord
+	w.Each(func(c Coord) bool {
+		if w.At(c) == r {
+			ret = append(ret, c)
+		}
+		return false
+	})
+	return ret
+}
+
+func (w *CowWorld) Rect() (minX, minY, maxX, maxY int) {
+	minX, maxX, minY, maxY = math.MaxInt, math.MinInt, math.MaxInt, math.MinInt
+	w.Each(func(c Coord) bool {
+		if c.X < minX {
+			minX = c.X
+		}
+		if c.X > maxX {
+			maxX = c.X
+		}
+		if c.Y < minY {
+			minY = c.Y
+		}
+		if c.Y > maxY {
+			maxY = c.Y
+		}
+		return false
+	})
+	return minX, minY, maxX, maxY
+}
+
+func (w *CowWorld) Print(opts ...PrintOption) {
+	minx, miny, maxx, maxy := w.Rect()
+
+	a, b, c := miny, func(y int) bool { return y <= maxy }, 1
+
+	for _, opt := range opts {
+		if opt == InvertY {
+			a, b, c = maxy, func(y int) bool { return y >= miny }, -1
+		}
+	}
+
+	for y := a; b(y); y += c {
+		sb := strings.Builder{}
+		for x := minx; x <= maxx; x++ {
+			if ch := w.At(C(x, y)); ch > 0 {
+				sb.WriteRune(ch)
+			} else {
+				sb.WriteRune(' ')
+			}
+		}
+		fmt.Println(sb.String())
+	}
+}
+
+// Len returns the number of set cells.
+func (w *CowWorld) Len() int {
+	if w.chunks == nil {
+		return 0
+	}
+	n := 0
+	for _, ch := range w.chunks.m {
+		n += ch.count
+	}
+	return n
+}
+
+// MarshalText implements encoding.TextMarshaler, like SparseWorld's.
+func (w *CowWorld) MarshalText() ([]byte, error) {
+	var blank rune
+	if n := w.Len(); n > 0 {
+		minX, minY, maxX, maxY := w.Rect()
+		if n != (maxX-minX+1)*(maxY-minY+1) {
+			blank = UnusedRune(w)
+		}
+	}
+	return MarshalWorld(w, TextHeader{Default: blank})
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, replacing the contents of
+// w with the parsed world.
+func (w *CowWorld) UnmarshalText(text []byte) error {
+	*w = CowWorld{}
+	_, err := UnmarshalWorld(w, text)
+	return err
+}
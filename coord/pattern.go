@@ -0,0 +1,268 @@
+package coord
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+// Transform is one of the eight rotations and reflections of a square grid.
+// Rotations are clockwise; the Flip transforms mirror left-to-right before
+// rotating.
+type Transform int
+
+const (
+	Identity Transform = iota
+	Rotate90
+	Rotate180
+	Rotate270
+	Flip
+	FlipRotate90
+	FlipRotate180
+	FlipRotate270
+)
+
+var Transforms = []Transform{
+	Identity, Rotate90, Rotate180, Rotate270,
+	Flip, FlipRotate90, FlipRotate180, FlipRotate270,
+}
+
+func (t Transform) String() string {
+	switch t {
+	case Identity:
+		return "identity"
+	case Rotate90:
+		return "rotate90"
+	case Rotate180:
+		return "rotate180"
+	case Rotate270:
+		return "rotate270"
+	case Flip:
+		return "flip"
+	case FlipRotate90:
+		return "flip+rotate90"
+	case FlipRotate180:
+		return "flip+rotate180"
+	case FlipRotate270:
+		return "flip+rotate270"
+	}
+	return fmt.Sprintf("(bad transform %d)", int(t))
+}
+
+// Apply transforms c about the origin.
+func (t Transform) Apply(c Coord) Coord {
+	if t >= Flip {
+		c.X = -c.X
+	}
+	for i := 0; i < int(t)%4; i++ {
+		// with Y pointing down, clockwise takes north (0,-1) to east (1,0)
+		c = Coord{-c.Y, c.X}
+	}
+	return c
+}
+
+// PatternOpts controls how FindPattern matches a pattern against a World.
+type PatternOpts struct {
+	// Wildcard, if non-zero, is a rune in the pattern that matches any cell.
+	// Cells that are unset in the pattern always match anything.
+	Wildcard rune
+
+	// Rotate also searches for the pattern rotated by 90, 180 and 270 degrees.
+	Rotate bool
+
+	// Reflect also searches for the pattern mirrored (and, if Rotate is set,
+	// each of its rotations).
+	Reflect bool
+}
+
+// Match is a single placement of a pattern found by FindPattern.
+type Match struct {
+	// At is the world coordinate of the top-left corner of the (transformed)
+	// pattern's bounding box.
+	At        Coord
+	Transform Transform
+	// Cells are the world coordinates covered by non-wildcard pattern cells.
+	Cells []Coord
+}
+
+type patternCell struct {
+	Coord
+	r rune
+}
+
+// FindPattern returns every placement of pattern within w. Transforms that
+// produce the same shape (e.g. rotations of a symmetric pattern) are only
+// reported once, under the first such Transform in Transforms order.
+func FindPattern(w World, pattern World, opts PatternOpts) []Match {
+	pMinX, pMinY, pMaxX, pMaxY := pattern.Rect()
+	var cells []patternCell
+	for y := pMinY; y <= pMaxY; y++ {
+		for x := pMinX; x <= pMaxX; x++ {
+			r := pattern.At(C(x, y))
+			if r <= 0 || opts.Wildcard != 0 && r == opts.Wildcard {
+				continue
+			}
+			cells = append(cells, patternCell{C(x-pMinX, y-pMinY), r})
+		}
+	}
+	if len(cells) == 0 {
+		return nil
+	}
+
+	transforms := []Transform{Identity}
+	if opts.Rotate {
+		transforms = append(transforms, Rotate90, Rotate180, Rotate270)
+	}
+	if opts.Reflect {
+		for _, t := range transforms {
+			transforms = append(transforms, t+Flip)
+		}
+	}
+
+	minX, minY, maxX, maxY := w.Rect()
+	var ret []Match
+	seen := map[string]bool{}
+	for _, t := range transforms {
+		shape, width, height := transformCells(cells, t)
+		key := fmt.Sprint(shape)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		for y := minY; y+height-1 <= maxY; y++ {
+		placement:
+			for x := minX; x+width-1 <= maxX; x++ {
+				at := C(x, y)
+				for _, c := range shape {
+					if w.At(at.Plus(c.Coord)) != c.r {
+						continue placement
+					}
+				}
+				m := Match{At: at, Transform: t, Cells: make([]Coord, len(shape))}
+				for i, c := range shape {
+					m.Cells[i] = at.Plus(c.Coord)
+				}
+				ret = append(ret, m)
+			}
+		}
+	}
+	return ret
+}
+
+// transformCells applies t to cells and translates the result so its bounding
+// box starts at (0,0). The result is sorted, so identical shapes compare equal.
+func transformCells(cells []patternCell, t Transform) (ret []patternCell, width, height int) {
+	ret = make([]patternCell, len(cells))
+	min := t.Apply(cells[0].Coord)
+	max := min
+	for i, c := range cells {
+		ret[i] = patternCell{t.Apply(c.Coord), c.r}
+		min.X, min.Y = minInt(min.X, ret[i].X), minInt(min.Y, ret[i].Y)
+		max.X, max.Y = maxInt(max.X, ret[i].X), maxInt(max.Y, ret[i].Y)
+	}
+	for i := range ret {
+		ret[i].Coord = ret[i].Minus(min)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Y != ret[j].Y {
+			return ret[i].Y < ret[j].Y
+		}
+		return ret[i].X < ret[j].X
+	})
+	return ret, max.X - min.X + 1, max.Y - min.Y + 1
+}
+
+// TransformWorld returns a copy of w with t applied, translated so that its
+// bounding box starts at (0,0). The result is dense if w is dense.
+func TransformWorld(w World, t Transform) World {
+	minX, minY, maxX, maxY := w.Rect()
+	if minX > maxX || minY > maxY {
+		return w.Copy()
+	}
+	var cells []patternCell
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			if r := w.At(C(x, y)); r > 0 {
+				cells = append(cells, patternCell{C(x-minX, y-minY), r})
+			}
+		}
+	}
+
+	ret := empty(w)
+	// opposite corners stay opposite under any Transform, so transforming them
+	// gives the new bounding box even if the world's edges are blank
+	corners := [2]Coord{{}, C(maxX-minX, maxY-minY)}
+	a, b := t.Apply(corners[0]), t.Apply(corners[1])
+	tMin := C(minInt(a.X, b.X), minInt(a.Y, b.Y))
+	for _, c := range cells {
+		ret.Set(t.Apply(c.Coord).Minus(tMin), c.r)
+	}
+	return ret
+}
+
+// Extract returns a new world holding the cells of w within r, translated so
+// that r.Min becomes (0,0). The result is dense if w is dense.
+func Extract(w World, r image.Rectangle) World {
+	ret := empty(w)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if c := w.At(C(x, y)); c > 0 {
+				ret.Set(C(x-r.Min.X, y-r.Min.Y), c)
+			}
+		}
+	}
+	return ret
+}
+
+// Paste copies every set cell of src into dst, offset by at; i.e., src's (0,0)
+// lands on at. Pasting the result of Extract at r.Min puts it back where it came
+// from. A DenseWorld can't hold negative coordinates, so pasting a cell there is
+// an error, and dst is left unchanged.
+func Paste(dst World, src World, at Coord) error {
+	minX, minY, maxX, maxY := src.Rect()
+	if _, dense := dst.(*DenseWorld); dense && minX <= maxX && minY <= maxY {
+		// only set cells are pasted, so find the top-left-most of those
+		lowX, lowY := maxX, maxY
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				if src.At(C(x, y)) > 0 {
+					lowX, lowY = minInt(lowX, x), minInt(lowY, y)
+				}
+			}
+		}
+		if c := C(lowX, lowY).Plus(at); c.X < 0 || c.Y < 0 {
+			return fmt.Errorf("dense world cannot hold %v", c)
+		}
+	}
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			if c := src.At(C(x, y)); c > 0 {
+				dst.Set(C(x, y).Plus(at), c)
+			}
+		}
+	}
+	return nil
+}
+
+// empty returns a new, empty world of the same kind as w.
+func empty(w World) World {
+	if _, ok := w.(*DenseWorld); ok {
+		return &DenseWorld{}
+	}
+	return &SparseWorld{}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
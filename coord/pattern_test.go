@@ -0,0 +1,92 @@
+package coord
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform_Apply(t *testing.T) {
+	tests := []struct {
+		t    Transform
+		want Coord
+	}{
+		{Identity, C(2, -1)},
+		{Rotate90, C(1, 2)},
+		{Rotate180, C(-2, 1)},
+		{Rotate270, C(-1, -2)},
+		{Flip, C(-2, -1)},
+		{FlipRotate90, C(1, -2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.t.String(), func(t *testing.T) {
+			require.Equal(t, tt.want, tt.t.Apply(C(2, -1)))
+		})
+	}
+}
+
+func TestFindPattern(t *testing.T) {
+	monster := Load([]string{
+		"                  # ",
+		"#    ##    ##    ###",
+		" #  #  #  #  #  #   ",
+	}, false)
+	world := Load([]string{
+		"......................",
+		"...................#..",
+		".#....##....##....###.",
+		"..#..#..#..#..#..#....",
+		"......................",
+	}, true)
+
+	got := FindPattern(world, monster, PatternOpts{Wildcard: ' '})
+	require.Len(t, got, 1)
+	require.Equal(t, C(1, 1), got[0].At)
+	require.Len(t, got[0].Cells, 15)
+
+	rotated := TransformWorld(world, Rotate90)
+	require.Empty(t, FindPattern(rotated, monster, PatternOpts{Wildcard: ' '}))
+	got = FindPattern(rotated, monster, PatternOpts{Wildcard: ' ', Rotate: true, Reflect: true})
+	require.Len(t, got, 1)
+	require.Equal(t, Rotate90, got[0].Transform)
+
+	flipped := TransformWorld(world, Flip)
+	got = FindPattern(flipped, monster, PatternOpts{Wildcard: ' ', Rotate: true, Reflect: true})
+	require.Len(t, got, 1)
+	require.Equal(t, Flip, got[0].Transform)
+}
+
+func TestFindPattern_Symmetric(t *testing.T) {
+	world := Load([]string{
+		"##.",
+		"##.",
+	}, false)
+	got := FindPattern(world, Load([]string{"##", "##"}, false), PatternOpts{Rotate: true, Reflect: true})
+	require.Len(t, got, 1)
+	require.Equal(t, Identity, got[0].Transform)
+}
+
+func TestExtractPaste(t *testing.T) {
+	world := Load([]string{
+		"abcd",
+		"efgh",
+		"ijkl",
+	}, true)
+	sub := Extract(world, image.Rect(1, 1, 3, 3))
+	require.Empty(t, Diff(Load([]string{"fg", "jk"}, true), sub))
+
+	target := &SparseWorld{}
+	require.NoError(t, Paste(target, sub, C(-5, 10)))
+	require.Equal(t, SparseWorld{C(-5, 10): 'f', C(-4, 10): 'g', C(-5, 11): 'j', C(-4, 11): 'k'}, *target)
+
+	require.NoError(t, Paste(world, TransformWorld(sub, Rotate180), C(1, 1)))
+	require.Empty(t, Diff(Load([]string{"abcd", "ekjh", "igfl"}, false), world))
+
+	// a dense world can't take cells at negative coordinates, and isn't touched
+	require.Error(t, Paste(world, sub, C(-1, 0)))
+	require.Empty(t, Diff(Load([]string{"abcd", "ekjh", "igfl"}, false), world))
+	// but cells that would be negative and are unset don't count
+	require.NoError(t, Paste(world, &SparseWorld{C(1, 0): 'x'}, C(-1, 0)))
+	require.Equal(t, 'x', world.At(C(0, 0)))
+}
@@ -0,0 +1,139 @@
+package coord
+
+import "fmt"
+
+// CardinalDirections are the four directions along rows and columns.
+var CardinalDirections = []Direction{North, East, South, West}
+
+// Sightlines holds what can be seen along each of the eight directions from
+// every cell of a height grid, like the trees of AoC 2022 day 8. Each grid is
+// indexed [y][x], with North toward y = 0.
+type Sightlines struct {
+	Width, Height int
+
+	// Visible[d] reports, for each cell, whether it can be seen from outside
+	// the grid in direction d: every cell between it and that edge is lower.
+	Visible [8][][]bool
+
+	// Distance[d] is, for each cell, how many cells it can see in direction d:
+	// up to and including the first cell at least as high, or up to the edge.
+	Distance [8][][]int
+}
+
+// Sight computes Sightlines for a rectangular grid of heights. Each direction
+// takes one pass along every line of cells, keeping a stack of the cells not
+// yet blocked from view, so the whole computation is linear in the size of
+// the grid.
+func Sight(heights [][]int) *Sightlines {
+	s := &Sightlines{Height: len(heights)}
+	if s.Height > 0 {
+		s.Width = len(heights[0])
+	}
+	for y, row := range heights {
+		if len(row) != s.Width {
+			panic(fmt.Sprintf("coord.Sight: row %d has %d cells, not %d", y, len(row), s.Width))
+		}
+	}
+
+	in := func(c Coord) bool {
+		return c.X >= 0 && c.X < s.Width && c.Y >= 0 && c.Y < s.Height
+	}
+
+	type entry struct {
+		pos, height int
+	}
+	var stack []entry
+	for _, d := range Directions {
+		visible := make([][]bool, s.Height)
+		distance := make([][]int, s.Height)
+		for y := range visible {
+			visible[y] = make([]bool, s.Width)
+			distance[y] = make([]int, s.Width)
+		}
+		s.Visible[d], s.Distance[d] = visible, distance
+
+		step := C(0, 0).Move(d)
+		for y := 0; y < s.Height; y++ {
+			for x := 0; x < s.Width; x++ {
+				start := C(x, y)
+				if in(start.Plus(step)) {
+					continue
+				}
+				// start is on the edge facing d; walk away from that edge,
+				// keeping the cells seen so far that nothing since has been
+				// as high as, so their heights never increase up the stack
+				stack = stack[:0]
+				for pos, c := 0, start; in(c); pos, c = pos+1, c.Minus(step) {
+					h := heights[c.Y][c.X]
+					for len(stack) > 0 && stack[len(stack)-1].height < h {
+						stack = stack[:len(stack)-1]
+					}
+					if len(stack) == 0 {
+						visible[c.Y][c.X] = true
+						distance[c.Y][c.X] = pos
+					} else {
+						distance[c.Y][c.X] = pos - stack[len(stack)-1].pos
+					}
+					stack = append(stack, entry{pos, h})
+				}
+			}
+		}
+	}
+	return s
+}
+
+// VisibleFrom reports whether c can be seen from outside the grid in any of
+// the given directions.
+func (s *Sightlines) VisibleFrom(c Coord, dirs []Direction) bool {
+	for _, d := range dirs {
+		if s.Visible[d][c.Y][c.X] {
+			return true
+		}
+	}
+	return false
+}
+
+// CountVisible returns how many cells can be seen from outside the grid in
+// any of the given directions.
+func (s *Sightlines) CountVisible(dirs []Direction) int {
+	n := 0
+	for y := 0; y < s.Height; y++ {
+		for x := 0; x < s.Width; x++ {
+			if s.VisibleFrom(C(x, y), dirs) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Scores returns, for each cell, the product of its viewing distances in the
+// given directions.
+func (s *Sightlines) Scores(dirs []Direction) [][]int {
+	ret := make([][]int, s.Height)
+	for y := range ret {
+		ret[y] = make([]int, s.Width)
+		for x := range ret[y] {
+			score := 1
+			for _, d := range dirs {
+				score *= s.Distance[d][y][x]
+			}
+			ret[y][x] = score
+		}
+	}
+	return ret
+}
+
+// Best returns the cell with the highest score in the given directions, the
+// first in reading order on a tie, and its score.
+func (s *Sightlines) Best(dirs []Direction) (Coord, int) {
+	best, bestScore := C(-1, -1), -1
+	for y, row := range s.Scores(dirs) {
+		for x, score := range row {
+			if score > bestScore {
+				best, bestScore = C(x, y), score
+			}
+		}
+	}
+	return best, bestScore
+}
@@ -0,0 +1,82 @@
+package coord
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func digitGrid(rows ...string) [][]int {
+	ret := make([][]int, len(rows))
+	for y, row := range rows {
+		for _, r := range row {
+			ret[y] = append(ret[y], int(r-'0'))
+		}
+	}
+	return ret
+}
+
+func TestSight(t *testing.T) {
+	trees := digitGrid(
+		"30373",
+		"25512",
+		"65332",
+		"33549",
+		"35390",
+	)
+	s := Sight(trees)
+	require.Equal(t, 21, s.CountVisible(CardinalDirections))
+
+	best, score := s.Best(CardinalDirections)
+	require.Equal(t, C(2, 3), best)
+	require.Equal(t, 8, score)
+
+	// the middle 5 of the second row
+	require.Equal(t, 1, s.Distance[North][1][2])
+	require.Equal(t, 1, s.Distance[West][1][2])
+	require.Equal(t, 2, s.Distance[South][1][2])
+	require.Equal(t, 2, s.Distance[East][1][2])
+	require.True(t, s.Visible[North][1][2])
+	require.False(t, s.Visible[South][1][2])
+
+	// diagonally, the 9 sees to the edge, but the 3 right of the middle is hidden
+	// behind the 5 to its upper left
+	require.True(t, s.Visible[NorthWest][3][4])
+	require.Equal(t, 3, s.Distance[NorthWest][3][4])
+	require.False(t, s.Visible[NorthWest][2][3])
+	require.Equal(t, 1, s.Distance[NorthWest][2][3])
+	require.Equal(t, 0, s.Distance[NorthEast][0][3])
+}
+
+// TestSight_Naive checks Sight against walking out from each cell.
+func TestSight_Naive(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	for _, size := range []Coord{{1, 1}, {7, 3}, {4, 9}, {12, 12}} {
+		heights := make([][]int, size.Y)
+		for y := range heights {
+			heights[y] = make([]int, size.X)
+			for x := range heights[y] {
+				heights[y][x] = rng.Intn(5)
+			}
+		}
+
+		s := Sight(heights)
+		for y, row := range heights {
+			for x, h := range row {
+				for _, d := range Directions {
+					visible, distance := true, 0
+					for c := C(x, y).Move(d); c.X >= 0 && c.X < size.X && c.Y >= 0 && c.Y < size.Y; c = c.Move(d) {
+						distance++
+						if heights[c.Y][c.X] >= h {
+							visible = false
+							break
+						}
+					}
+					require.Equal(t, visible, s.Visible[d][y][x], "%v at %d,%d", d, x, y)
+					require.Equal(t, distance, s.Distance[d][y][x], "%v at %d,%d", d, x, y)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package coord
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// HeaderPrefix marks the optional metadata lines at the top of a world's text
+// form. Header lines must all come before the first grid line.
+const HeaderPrefix = "%% "
+
+// TextHeader is the optional metadata carried along with a World's text form.
+type TextHeader struct {
+	// Origin is the coordinate of the first character of the first grid line.
+	// When marshalling, the zero Origin means the top-left of the world's
+	// Rect.
+	Origin Coord
+
+	// Default, if non-zero, is the rune written for cells that are not set. When
+	// loading, cells holding Default are left unset, so a world can't round
+	// trip if any of its cells is set to Default; MarshalWorld refuses it.
+	Default rune
+
+	// Legend describes what the runes in the grid mean. It's not used by the
+	// codec itself, it's just carried along.
+	Legend map[rune]string
+}
+
+// ErrDefaultInUse is returned when marshalling a world with a cell set to the
+// header's Default, which would be left unset when it's loaded again.
+var ErrDefaultInUse = errors.New("default rune is set in the world")
+
+// ErrHeaderRow is returned when marshalling a world whose first grid line
+// starts with HeaderPrefix, which would be read back as a header line.
+var ErrHeaderRow = errors.New("first grid line looks like a header")
+
+// MarshalWorld renders w as text, preceded by a header describing h. The grid
+// starts at h.Origin, if it's non-zero, and otherwise at the top-left of w's
+// Rect. It's an error for w to have cells above or left of the origin, cells
+// set to h.Default, or a first grid line starting with HeaderPrefix.
+func MarshalWorld(w World, h TextHeader) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	minX, minY, maxX, maxY := w.Rect()
+	if minX > maxX || minY > maxY {
+		minX, minY, maxX, maxY = h.Origin.X, h.Origin.Y, h.Origin.X-1, h.Origin.Y-1
+	}
+	if h.Origin != (Coord{}) {
+		if minX < h.Origin.X || minY < h.Origin.Y {
+			return nil, fmt.Errorf("world has cells at %d,%d, before origin %v", minX, minY, h.Origin)
+		}
+		minX, minY = h.Origin.X, h.Origin.Y
+	}
+	h.Origin = C(minX, minY)
+
+	if h.Origin != (Coord{}) {
+		fmt.Fprintf(buf, "%sorigin %d,%d\n", HeaderPrefix, h.Origin.X, h.Origin.Y)
+	}
+	if h.Default != 0 {
+		fmt.Fprintf(buf, "%sdefault %s\n", HeaderPrefix, strconv.QuoteRune(h.Default))
+	}
+	legend := make([]rune, 0, len(h.Legend))
+	for r := range h.Legend {
+		legend = append(legend, r)
+	}
+	sort.Slice(legend, func(i, j int) bool { return legend[i] < legend[j] })
+	for _, r := range legend {
+		fmt.Fprintf(buf, "%slegend %s %s\n", HeaderPrefix, strconv.QuoteRune(r), h.Legend[r])
+	}
+
+	blank := h.Default
+	if blank == 0 {
+		blank = ' '
+	}
+	grid := buf.Len()
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			r := w.At(C(x, y))
+			if r <= 0 {
+				r = blank
+			} else if r == h.Default {
+				return nil, fmt.Errorf("%w: %s at %d,%d", ErrDefaultInUse, strconv.QuoteRune(r), x, y)
+			}
+			buf.WriteRune(r)
+		}
+		if y == minY && bytes.HasPrefix(buf.Bytes()[grid:], []byte(HeaderPrefix)) {
+			return nil, fmt.Errorf("%w: %q", ErrHeaderRow, buf.Bytes()[grid:])
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// blankCandidates are the runes UnusedRune tries first, in order.
+var blankCandidates = []rune{' ', '.', '\u00b7', '~', '?'}
+
+// UnusedRune returns a rune that no cell of w is set to, suitable as a
+// TextHeader's Default: a space if possible, and otherwise some other rune
+// that's easy to read, or failing that one from the private use area.
+func UnusedRune(w World) rune {
+	used := map[rune]bool{}
+	minX, minY, maxX, maxY := w.Rect()
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			used[w.At(C(x, y))] = true
+		}
+	}
+	for _, r := range blankCandidates {
+		if !used[r] {
+			return r
+		}
+	}
+	r := '\ue000'
+	for used[r] {
+		r++
+	}
+	return r
+}
+
+// UnmarshalWorld parses text produced by MarshalWorld (or plain grid lines, as
+// accepted by Load) into w, returning the parsed header.
+func UnmarshalWorld(w World, text []byte) (TextHeader, error) {
+	var h TextHeader
+	text = bytes.ReplaceAll(text, []byte("\r"), nil)
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+	scanner.Buffer(nil, len(text)+1)
+
+	inHeader := true
+	y := 0
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if inHeader && strings.HasPrefix(line, HeaderPrefix) {
+			if err := h.parse(strings.TrimPrefix(line, HeaderPrefix)); err != nil {
+				return h, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		inHeader = false
+
+		for x, r := range []rune(line) {
+			if h.Default != 0 && r == h.Default {
+				continue
+			}
+			c := C(x, y).Plus(h.Origin)
+			if _, dense := w.(*DenseWorld); dense && (c.X < 0 || c.Y < 0) {
+				return h, fmt.Errorf("line %d: dense world cannot hold %v", lineNo, c)
+			}
+			w.Set(c, r)
+		}
+		y++
+	}
+	return h, scanner.Err()
+}
+
+func (h *TextHeader) parse(line string) error {
+	key, value := line, ""
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		key, value = line[:i], line[i+1:]
+	}
+
+	switch key {
+	case "origin":
+		c, err := FromComma(value)
+		if err != nil {
+			return fmt.Errorf("bad origin %q: %w", value, err)
+		}
+		h.Origin = c
+	case "default":
+		r, rest, err := unquoteRune(value)
+		if err != nil || rest != "" {
+			return fmt.Errorf("bad default %q", value)
+		}
+		h.Default = r
+	case "legend":
+		r, rest, err := unquoteRune(value)
+		if err != nil {
+			return fmt.Errorf("bad legend %q: %w", value, err)
+		}
+		if h.Legend == nil {
+			h.Legend = map[rune]string{}
+		}
+		h.Legend[r] = strings.TrimPrefix(rest, " ")
+	default:
+		return fmt.Errorf("unknown header %q", key)
+	}
+	return nil
+}
+
+// unquoteRune parses a single-quoted rune literal from the front of s and
+// returns the rest of s following it.
+func unquoteRune(s string) (rune, string, error) {
+	if !strings.HasPrefix(s, "'") {
+		return 0, s, fmt.Errorf("expected quoted rune")
+	}
+	// the closing quote is the first unescaped ' after the opening one
+	for i := 1; i < len(s); {
+		if s[i] == '\\' {
+			i += 2
+			continue
+		}
+		if s[i] == '\'' {
+			r, _, tail, err := strconv.UnquoteChar(s[1:i], '\'')
+			if err != nil || tail != "" {
+				return 0, s, fmt.Errorf("bad rune literal %s", s[:i+1])
+			}
+			return r, s[i+1:], nil
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return 0, s, fmt.Errorf("unterminated rune literal")
+}
+
+// MarshalText implements encoding.TextMarshaler. Unset cells are written as
+// spaces (or, if some cell is a space, another UnusedRune) and recorded as the
+// default, and a non-zero origin is recorded in the header, so negative
+// coordinates survive a round trip.
+func (w SparseWorld) MarshalText() ([]byte, error) {
+	return MarshalWorld(w, TextHeader{Default: w.blank()})
+}
+
+// blank returns the default rune to use when marshalling, or 0 if every cell
+// in the world's Rect is set.
+func (w SparseWorld) blank() rune {
+	if len(w) == 0 {
+		return 0
+	}
+	minX, minY, maxX, maxY := w.Rect()
+	if len(w) == (maxX-minX+1)*(maxY-minY+1) {
+		return 0
+	}
+	return UnusedRune(w)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, replacing the contents of
+// w with the parsed world.
+func (w *SparseWorld) UnmarshalText(text []byte) error {
+	*w = SparseWorld{}
+	_, err := UnmarshalWorld(w, text)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler. Zero cells are written as
+// spaces, or another UnusedRune, and recorded as the default.
+func (d DenseWorld) MarshalText() ([]byte, error) {
+	var blank rune
+	_, _, maxX, _ := d.Rect()
+	for _, row := range d {
+		if len(row) <= maxX {
+			blank = UnusedRune(&d)
+			break
+		}
+		for _, cell := range row {
+			if cell == 0 {
+				blank = UnusedRune(&d)
+				break
+			}
+		}
+		if blank != 0 {
+			break
+		}
+	}
+	return MarshalWorld(&d, TextHeader{Default: blank})
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, replacing the contents of
+// d with the parsed world. Origins with negative coordinates are an error.
+func (d *DenseWorld) UnmarshalText(text []byte) error {
+	*d = DenseWorld{}
+	_, err := UnmarshalWorld(d, text)
+	return err
+}
+
+// Diff returns a human-readable description of the cells that differ between
+// want and got, or the empty string if they hold the same cells. Unset cells
+// compare equal to spaces, whether they're absent, zero, or out of bounds.
+func Diff(want, got World) string {
+	minX, minY, maxX, maxY := unionRect(want, got)
+
+	var diffs int
+	sb := &strings.Builder{}
+	for y := minY; y <= maxY; y++ {
+		var wantRow, gotRow, marks strings.Builder
+		rowDiff := false
+		for x := minX; x <= maxX; x++ {
+			a, b := want.At(C(x, y)), got.At(C(x, y))
+			wantRow.WriteRune(printable(a))
+			gotRow.WriteRune(printable(b))
+			if printable(a) != printable(b) {
+				marks.WriteRune('^')
+				rowDiff = true
+				diffs++
+			} else {
+				marks.WriteRune(' ')
+			}
+		}
+		flag := ' '
+		if rowDiff {
+			flag = '!'
+		}
+		fmt.Fprintf(sb, "%c %6d | %s | %s\n", flag, y, wantRow.String(), gotRow.String())
+		if rowDiff {
+			fmt.Fprintf(sb, "         | %s | %s\n", marks.String(), marks.String())
+		}
+	}
+	if diffs == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d cell(s) differ, x from %d to %d (want | got):\n%s", diffs, minX, maxX, sb.String())
+}
+
+func printable(r rune) rune {
+	if r <= 0 {
+		return ' '
+	}
+	return r
+}
+
+func unionRect(a, b World) (minX, minY, maxX, maxY int) {
+	aMinX, aMinY, aMaxX, aMaxY := a.Rect()
+	bMinX, bMinY, bMaxX, bMaxY := b.Rect()
+	minX, minY, maxX, maxY = aMinX, aMinY, aMaxX, aMaxY
+	if bMinX < minX {
+		minX = bMinX
+	}
+	if bMinY < minY {
+		minY = bMinY
+	}
+	if bMaxX > maxX {
+		maxX = bMaxX
+	}
+	if bMaxY > maxY {
+		maxY = bMaxY
+	}
+	return minX, minY, maxX, maxY
+}
@@ -0,0 +1,96 @@
+package coord
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorld_TextRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		world World
+		text  string
+	}{
+		{"plain dense", Load([]string{"#.#", "..#"}, true), "#.#\n..#\n"},
+		{"plain sparse", Load([]string{"#.#", "..#"}, false), "#.#\n..#\n"},
+		{"jagged dense", Load([]string{"#.#", "."}, true), "%% default ' '\n#.#\n.  \n"},
+		{"negative sparse", &SparseWorld{C(-3, -2): '#', C(-1, -1): '@'},
+			"%% origin -3,-2\n%% default ' '\n#  \n  @\n"},
+		{"empty sparse", &SparseWorld{}, ""},
+		{"sparse with spaces", &SparseWorld{C(0, 0): ' ', C(2, 0): '#'}, "%% default '.'\n .#\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := tt.world.(interface{ MarshalText() ([]byte, error) }).MarshalText()
+			require.NoError(t, err)
+			require.Equal(t, tt.text, string(text))
+
+			var got World
+			if _, ok := tt.world.(*DenseWorld); ok {
+				d := &DenseWorld{}
+				require.NoError(t, d.UnmarshalText(text))
+				got = d
+			} else {
+				s := &SparseWorld{}
+				require.NoError(t, s.UnmarshalText(text))
+				got = s
+			}
+			require.Empty(t, Diff(tt.world, got))
+		})
+	}
+}
+
+func TestUnmarshalWorld_Header(t *testing.T) {
+	w := &SparseWorld{}
+	h, err := UnmarshalWorld(w, []byte("%% origin 5,-7\n%% default '.'\n%% legend '\\'' a quote\n%% legend '#' wall\n.'#\n"))
+	require.NoError(t, err)
+	require.Equal(t, TextHeader{
+		Origin:  C(5, -7),
+		Default: '.',
+		Legend:  map[rune]string{'\'': "a quote", '#': "wall"},
+	}, h)
+	require.Equal(t, SparseWorld{C(6, -7): '\'', C(7, -7): '#'}, *w)
+
+	_, err = UnmarshalWorld(&DenseWorld{}, []byte("%% origin -1,0\n#\n"))
+	require.Error(t, err)
+
+	_, err = UnmarshalWorld(&SparseWorld{}, []byte("%% bogus 1\n#\n"))
+	require.Error(t, err)
+}
+
+func TestMarshalWorld(t *testing.T) {
+	w := &SparseWorld{C(2, 1): '#', C(3, 1): ' '}
+
+	_, err := MarshalWorld(w, TextHeader{Default: ' '})
+	require.True(t, errors.Is(err, ErrDefaultInUse))
+
+	// a first row that reads as a header can't round trip
+	_, err = MarshalWorld(&SparseWorld{C(0, 0): '%', C(1, 0): '%', C(3, 0): '#'}, TextHeader{})
+	require.True(t, errors.Is(err, ErrHeaderRow))
+	_, err = MarshalWorld(&SparseWorld{C(0, 0): '%', C(1, 0): '%', C(3, 0): '#'}, TextHeader{Default: '.'})
+	require.NoError(t, err)
+
+	text, err := MarshalWorld(w, TextHeader{Origin: C(1, -1), Default: '.'})
+	require.NoError(t, err)
+	require.Equal(t, "%% origin 1,-1\n%% default '.'\n...\n...\n.# \n", string(text))
+	got := &SparseWorld{}
+	_, err = UnmarshalWorld(got, text)
+	require.NoError(t, err)
+	require.Equal(t, *w, *got)
+
+	_, err = MarshalWorld(w, TextHeader{Origin: C(3, 0)})
+	require.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	a := Load([]string{"#.#"}, false)
+	b := Load([]string{"#x#"}, true)
+	require.Equal(t,
+		"1 cell(s) differ, x from 0 to 2 (want | got):\n"+
+			"!      0 | #.# | #x#\n"+
+			"         |  ^  |  ^ \n",
+		Diff(a, b))
+}
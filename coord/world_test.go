@@ -0,0 +1,138 @@
+package coord
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var worldTypes = []struct {
+	name  string
+	new   func() World
+	dense bool
+}{
+	{"sparse", func() World { return &SparseWorld{} }, false},
+	{"dense", func() World { return &DenseWorld{} }, true},
+	{"cow", func() World { return &CowWorld{} }, false},
+}
+
+func sorted(cs []Coord) []Coord {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Y != cs[j].Y {
+			return cs[i].Y < cs[j].Y
+		}
+		return cs[i].X < cs[j].X
+	})
+	return cs
+}
+
+func TestWorld_Interface(t *testing.T) {
+	for _, wt := range worldTypes {
+		t.Run(wt.name, func(t *testing.T) {
+			w := wt.new()
+			require.LessOrEqual(t, w.At(C(3, 3)), rune(0), "unset cell")
+
+			w.Set(C(1, 2), '#')
+			w.Set(C(20, 0), '#')
+			w.Set(C(5, 17), '@')
+			require.Equal(t, '#', w.At(C(1, 2)))
+			require.Equal(t, '@', w.At(C(5, 17)))
+			require.LessOrEqual(t, w.At(C(2, 1)), rune(0), "unset cell")
+
+			require.Equal(t, []Coord{C(20, 0), C(1, 2)}, sorted(w.Find('#')))
+
+			minX, minY, maxX, maxY := w.Rect()
+			if !wt.dense {
+				require.Equal(t, []int{1, 0, 20, 17}, []int{minX, minY, maxX, maxY})
+			} else {
+				require.Equal(t, []int{20, 17}, []int{maxX, maxY})
+			}
+
+			var set []Coord
+			w.Each(func(c Coord) bool {
+				if w.At(c) > 0 {
+					set = append(set, c)
+				}
+				return false
+			})
+			require.Equal(t, []Coord{C(20, 0), C(1, 2), C(5, 17)}, sorted(set))
+
+			stops := 0
+			w.Each(func(c Coord) bool {
+				stops++
+				return true
+			})
+			require.Equal(t, 1, stops)
+		})
+	}
+}
+
+func TestWorld_CopyIsolation(t *testing.T) {
+	for _, wt := range worldTypes {
+		t.Run(wt.name, func(t *testing.T) {
+			w := wt.new()
+			w.Set(C(0, 0), 'a')
+			w.Set(C(40, 40), 'b')
+
+			cp := w.Copy()
+			cp2 := cp.Copy()
+			w.Set(C(0, 0), 'x')
+			cp.Set(C(40, 40), 'y')
+			cp.Set(C(1, 1), 'z')
+
+			require.Equal(t, 'x', w.At(C(0, 0)))
+			require.Equal(t, 'b', w.At(C(40, 40)))
+			require.LessOrEqual(t, w.At(C(1, 1)), rune(0))
+
+			require.Equal(t, 'a', cp.At(C(0, 0)))
+			require.Equal(t, 'y', cp.At(C(40, 40)))
+			require.Equal(t, 'z', cp.At(C(1, 1)))
+
+			require.Equal(t, 'a', cp2.At(C(0, 0)))
+			require.Equal(t, 'b', cp2.At(C(40, 40)))
+		})
+	}
+}
+
+func TestCowWorld(t *testing.T) {
+	w := &CowWorld{}
+	for _, c := range []Coord{C(-1, -1), C(-16, 0), C(-17, 15), C(16, -16)} {
+		w.Set(c, '#')
+	}
+	require.Equal(t, 4, w.Len())
+	require.Equal(t, []Coord{C(16, -16), C(-1, -1), C(-16, 0), C(-17, 15)}, sorted(w.Find('#')))
+
+	minX, minY, maxX, maxY := w.Rect()
+	require.Equal(t, []int{-17, -16, 16, 15}, []int{minX, minY, maxX, maxY})
+
+	cp := w.Copy().(*CowWorld)
+	cp.Set(C(-1, -1), 0)
+	require.Equal(t, 3, cp.Len())
+	require.Equal(t, 4, w.Len())
+	require.Equal(t, -1, int(cp.At(C(-1, -1))))
+
+	text, err := w.MarshalText()
+	require.NoError(t, err)
+	rt := &CowWorld{}
+	require.NoError(t, rt.UnmarshalText(text))
+	require.Empty(t, Diff(w, rt))
+}
+
+func BenchmarkWorld_CopyAndSet(b *testing.B) {
+	for _, wt := range worldTypes {
+		b.Run(wt.name, func(b *testing.B) {
+			w := wt.new()
+			for y := 0; y < 100; y++ {
+				for x := 0; x < 100; x++ {
+					w.Set(C(x, y), '.')
+				}
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				w = w.Copy()
+				w.Set(C(i%100, i%97), '#')
+			}
+		})
+	}
+}
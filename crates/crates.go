@@ -0,0 +1,291 @@
+// Package crates simulates the supply stacks of AoC 2022 day 5: crates in
+// stacks, drawn as an ASCII diagram, rearranged by a crane following a list of
+// moves.
+package crates
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type Crate rune
+
+// Stack holds crates from the bottom up.
+type Stack []Crate
+
+// Top returns the top crate of s, if there is one.
+func (s Stack) Top() (Crate, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+	return s[len(s)-1], true
+}
+
+// Yard is a row of stacks, labeled as in the diagram's number row.
+type Yard struct {
+	Labels []string
+	Stacks []Stack
+}
+
+// ParseDiagram reads a diagram: rows of bracketed crates, like "[Z] [M] [P]",
+// over a row of stack labels, like " 1   2   3 ". Each crate must be directly
+// above its stack's label.
+func ParseDiagram(lines []string) (*Yard, error) {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("empty diagram")
+	}
+
+	labelRow := lines[len(lines)-1]
+	if strings.ContainsAny(labelRow, "[]") {
+		return nil, errors.New("diagram has no stack labels")
+	}
+	y := &Yard{}
+	var cols []int
+	for i := 0; i < len(labelRow); {
+		if labelRow[i] == ' ' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(labelRow) && labelRow[j] != ' ' {
+			j++
+		}
+		y.Labels = append(y.Labels, labelRow[i:j])
+		// crates are centered over their labels
+		cols = append(cols, (i+j-1)/2)
+		i = j
+	}
+	if len(y.Labels) == 0 {
+		return nil, errors.New("diagram has no stack labels")
+	}
+	y.Stacks = make([]Stack, len(y.Labels))
+
+	// read crate rows from the bottom up
+	for row := len(lines) - 2; row >= 0; row-- {
+		line := lines[row]
+		covered := make([]bool, len(line))
+		for s, col := range cols {
+			if col >= len(line) || line[col] == ' ' {
+				continue
+			}
+			if col == 0 || col+1 >= len(line) || line[col-1] != '[' || line[col+1] != ']' {
+				return nil, fmt.Errorf("row %d, column %d: expected a crate like [X]", row+1, col+1)
+			}
+			if len(y.Stacks[s]) != len(lines)-2-row {
+				return nil, fmt.Errorf("row %d, column %d: crate %c is floating", row+1, col+1, line[col])
+			}
+			y.Stacks[s] = append(y.Stacks[s], Crate(line[col]))
+			covered[col-1], covered[col], covered[col+1] = true, true, true
+		}
+		for col, c := range line {
+			if c != ' ' && !covered[col] {
+				return nil, fmt.Errorf("row %d, column %d: unexpected %q not over a stack label", row+1, col+1, c)
+			}
+		}
+	}
+	return y, nil
+}
+
+// Copy returns a deep copy of y.
+func (y *Yard) Copy() *Yard {
+	ret := &Yard{
+		Labels: append([]string(nil), y.Labels...),
+		Stacks: make([]Stack, len(y.Stacks)),
+	}
+	for i, s := range y.Stacks {
+		ret.Stacks[i] = append(Stack(nil), s...)
+	}
+	return ret
+}
+
+// Tops returns the top crate of each stack, skipping empty stacks.
+func (y *Yard) Tops() string {
+	var ret []rune
+	for _, s := range y.Stacks {
+		if c, ok := s.Top(); ok {
+			ret = append(ret, rune(c))
+		}
+	}
+	return string(ret)
+}
+
+// Height returns the height of the tallest stack.
+func (y *Yard) Height() int {
+	h := 0
+	for _, s := range y.Stacks {
+		if len(s) > h {
+			h = len(s)
+		}
+	}
+	return h
+}
+
+// Index returns the index of the stack with the given label, or -1.
+func (y *Yard) Index(label string) int {
+	for i, l := range y.Labels {
+		if l == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// String draws y as a diagram in the same format ParseDiagram reads, with
+// every stack four columns apart.
+func (y *Yard) String() string {
+	sb := &strings.Builder{}
+	for row := y.Height() - 1; row >= 0; row-- {
+		line := &strings.Builder{}
+		for i, s := range y.Stacks {
+			if i > 0 {
+				line.WriteByte(' ')
+			}
+			if row < len(s) {
+				fmt.Fprintf(line, "[%c]", s[row])
+			} else {
+				line.WriteString("   ")
+			}
+		}
+		sb.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+	line := &strings.Builder{}
+	for i, l := range y.Labels {
+		if i > 0 {
+			line.WriteByte(' ')
+		}
+		// center the label in three columns, leaning left
+		pad := 3 - len(l)
+		if pad < 0 {
+			pad = 0
+		}
+		line.WriteString(strings.Repeat(" ", (pad+1)/2) + l + strings.Repeat(" ", pad/2))
+	}
+	sb.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	return sb.String()
+}
+
+// Move moves N crates from the stack labeled From to the stack labeled To.
+type Move struct {
+	N        int
+	From, To string
+}
+
+func (m Move) String() string {
+	return fmt.Sprintf("move %d from %s to %s", m.N, m.From, m.To)
+}
+
+// ParseMove reads a move like "move 1 from 2 to 1".
+func ParseMove(s string) (Move, error) {
+	f := strings.Fields(s)
+	if len(f) != 6 || f[0] != "move" || f[2] != "from" || f[4] != "to" {
+		return Move{}, fmt.Errorf("expected move N from A to B, got %q", s)
+	}
+	n, err := strconv.Atoi(f[1])
+	if err != nil || n < 0 {
+		return Move{}, fmt.Errorf("bad count %q in %q", f[1], s)
+	}
+	return Move{N: n, From: f[3], To: f[5]}, nil
+}
+
+// Parse reads a diagram, a blank line, and then moves, one per line.
+func Parse(r io.Reader) (*Yard, []Move, error) {
+	scanner := bufio.NewScanner(r)
+	var diagram []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		diagram = append(diagram, line)
+	}
+	y, err := ParseDiagram(diagram)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var moves []Move
+	for lineNo := len(diagram) + 2; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		m, err := ParseMove(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		moves = append(moves, m)
+	}
+	return y, moves, scanner.Err()
+}
+
+// Crane is a model of crane, which determines how multiple crates are moved.
+type Crane int
+
+const (
+	// CrateMover9000 moves crates one at a time, so moving several reverses
+	// their order.
+	CrateMover9000 Crane = 9000
+	// CrateMover9001 moves several crates at once, keeping their order.
+	CrateMover9001 Crane = 9001
+)
+
+func (c Crane) String() string {
+	return fmt.Sprintf("CrateMover %d", int(c))
+}
+
+var (
+	ErrNoSuchStack = errors.New("no such stack")
+	ErrNotEnough   = errors.New("not enough crates")
+	ErrBadCrane    = errors.New("unknown crane")
+	ErrBadCount    = errors.New("negative crate count")
+)
+
+// Apply performs m on y with crane c. If m isn't possible, Apply returns an
+// error and leaves y unchanged.
+func (y *Yard) Apply(c Crane, m Move) error {
+	from, to := y.Index(m.From), y.Index(m.To)
+	switch {
+	case c != CrateMover9000 && c != CrateMover9001:
+		return fmt.Errorf("%w %d", ErrBadCrane, int(c))
+	case from < 0:
+		return fmt.Errorf("%v: %w %q", m, ErrNoSuchStack, m.From)
+	case to < 0:
+		return fmt.Errorf("%v: %w %q", m, ErrNoSuchStack, m.To)
+	case m.N < 0:
+		return fmt.Errorf("%v: %w", m, ErrBadCount)
+	case m.N > len(y.Stacks[from]):
+		return fmt.Errorf("%v: %w: stack %s has %d", m, ErrNotEnough, m.From, len(y.Stacks[from]))
+	}
+
+	src := y.Stacks[from]
+	lifted := append(Stack(nil), src[len(src)-m.N:]...)
+	y.Stacks[from] = src[:len(src)-m.N]
+	if c == CrateMover9000 {
+		for i, j := 0, len(lifted)-1; i < j; i, j = i+1, j-1 {
+			lifted[i], lifted[j] = lifted[j], lifted[i]
+		}
+	}
+	y.Stacks[to] = append(y.Stacks[to], lifted...)
+	return nil
+}
+
+// Run applies the moves in order, calling each hook after every move. It stops
+// at the first move that fails, returning an error that says which.
+func (y *Yard) Run(c Crane, moves []Move, hooks ...func(step int, m Move, y *Yard)) error {
+	for i, m := range moves {
+		if err := y.Apply(c, m); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		for _, hook := range hooks {
+			hook(i+1, m, y)
+		}
+	}
+	return nil
+}
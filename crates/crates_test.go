@@ -0,0 +1,105 @@
+package crates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const example = `    [D]    
+[N] [C]    
+[Z] [M] [P]
+ 1   2   3 
+
+move 1 from 2 to 1
+move 3 from 1 to 3
+move 2 from 2 to 1
+move 1 from 1 to 2
+`
+
+func TestParse(t *testing.T) {
+	y, moves, err := Parse(strings.NewReader(example))
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2", "3"}, y.Labels)
+	require.Equal(t, []Stack{{'Z', 'N'}, {'M', 'C', 'D'}, {'P'}}, y.Stacks)
+	require.Equal(t, Move{N: 1, From: "2", To: "1"}, moves[0])
+	require.Len(t, moves, 4)
+	require.Equal(t, "    [D]\n[N] [C]\n[Z] [M] [P]\n 1   2   3\n", y.String())
+
+	again, err := ParseDiagram(strings.Split(y.String(), "\n"))
+	require.NoError(t, err)
+	require.Equal(t, y, again)
+}
+
+func TestYard_Run(t *testing.T) {
+	tests := []struct {
+		crane Crane
+		want  string
+	}{
+		{CrateMover9000, "CMZ"},
+		{CrateMover9001, "MCD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.crane.String(), func(t *testing.T) {
+			y, moves, err := Parse(strings.NewReader(example))
+			require.NoError(t, err)
+
+			steps := 0
+			err = y.Run(tt.crane, moves, func(step int, m Move, y *Yard) {
+				steps++
+				require.Equal(t, steps, step)
+			})
+			require.NoError(t, err)
+			require.Equal(t, len(moves), steps)
+			require.Equal(t, tt.want, y.Tops())
+		})
+	}
+}
+
+func TestYard_Apply_Errors(t *testing.T) {
+	y, _, err := Parse(strings.NewReader(example))
+	require.NoError(t, err)
+	before := y.Copy()
+
+	require.ErrorIs(t, y.Apply(CrateMover9000, Move{N: 2, From: "3", To: "1"}), ErrNotEnough)
+	require.ErrorIs(t, y.Apply(CrateMover9001, Move{N: 1, From: "4", To: "1"}), ErrNoSuchStack)
+	require.ErrorIs(t, y.Apply(CrateMover9001, Move{N: 1, From: "1", To: "0"}), ErrNoSuchStack)
+	require.ErrorIs(t, y.Apply(Crane(1), Move{N: 1, From: "1", To: "2"}), ErrBadCrane)
+	require.ErrorIs(t, y.Apply(CrateMover9001, Move{N: -1, From: "1", To: "2"}), ErrBadCount)
+	require.Equal(t, before, y)
+
+	err = y.Run(CrateMover9000, []Move{{1, "3", "1"}, {1, "3", "2"}})
+	require.ErrorIs(t, err, ErrNotEnough)
+	require.Contains(t, err.Error(), "step 2")
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, diagram := range []string{
+		"",
+		"[A]\n",
+		"[A]\n   \n 1",
+		"[A [B]\n 1   2",
+		"    [B]\n[A]\n 1   2",
+		"[A]  x\n 1   2",
+		"[A]\n 1\n\nmove one from 1 to 2",
+	} {
+		_, _, err := Parse(strings.NewReader(diagram))
+		require.Error(t, err, diagram)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	y, moves, err := Parse(strings.NewReader(example))
+	require.NoError(t, err)
+
+	frames, err := Frames(CrateMover9001, y, moves)
+	require.NoError(t, err)
+	require.Len(t, frames, len(moves)+1)
+	// the original is untouched
+	require.Equal(t, "NDP", y.Tops())
+
+	frames, err = Frames(CrateMover9000, y, append(moves, Move{N: 9, From: "1", To: "2"}))
+	require.ErrorIs(t, err, ErrNotEnough)
+	require.Len(t, frames, len(moves)+1)
+}
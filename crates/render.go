@@ -0,0 +1,61 @@
+package crates
+
+import (
+	"fmt"
+
+	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/canvas"
+)
+
+// Canvas draws y as a diagram, leaving room for stacks up to height crates
+// tall so that successive frames line up. The top moved crates of the stack
+// labeled by m.To are highlighted, unless m is nil. The caption is shown above.
+func (y *Yard) Canvas(height int, caption string, m *Move) *canvas.Canvas {
+	cnv := &canvas.Canvas{}
+	width := aoc.Max(4*len(y.Stacks)-1, len(caption))
+	canvas.TextBox{
+		Title:      []rune("Supply Stacks"),
+		Body:       []rune(caption),
+		Footer:     []rune(y.Tops()),
+		BodyColor:  aoc.TolVibrantOrange,
+		TitleColor: aoc.TolVibrantTeal,
+		FrameColor: aoc.TolVibrantGrey,
+		Width:      width,
+	}.On(cnv)
+
+	moved := -1
+	if m != nil {
+		moved = y.Index(m.To)
+	}
+	bottom := 3 + height
+	for i, s := range y.Stacks {
+		x := 4*i + 1
+		for h, c := range s {
+			color := aoc.TolVibrantBlue
+			if i == moved && h >= len(s)-m.N {
+				color = aoc.TolVibrantMagenta
+			}
+			cnv.PrintAt(x, bottom-1-h, fmt.Sprintf("[%c]", c), color)
+		}
+		cnv.PrintAt(x+1, bottom, y.Labels[i], aoc.TolVibrantGrey)
+	}
+	return cnv
+}
+
+// Frames runs the moves on a copy of y with crane c, and draws the initial
+// yard and the yard after each move. It returns the frames drawn before any
+// error, along with the error.
+func Frames(c Crane, y *Yard, moves []Move) ([]*canvas.Canvas, error) {
+	y = y.Copy()
+	height := 0
+	for _, s := range y.Stacks {
+		height += len(s)
+	}
+
+	frames := []*canvas.Canvas{y.Canvas(height, c.String(), nil)}
+	err := y.Run(c, moves, func(step int, m Move, y *Yard) {
+		caption := fmt.Sprintf("%d/%d: %v", step, len(moves), m)
+		frames = append(frames, y.Canvas(height, caption, &m))
+	})
+	return frames, err
+}
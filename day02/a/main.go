@@ -3,51 +3,27 @@ package main
 import (
 	"bytes"
 	"os"
-	"strings"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/asymmetricia/aoc22/rps"
 )
 
 var log = logrus.StandardLogger()
 
-func outcome(game string) int {
-	switch strings.Replace(game, " ", "", -1) {
-	case "AX":
-		return 1 + 3
-	case "AY":
-		return 2 + 6
-	case "AZ":
-		return 3 + 0
-	case "BX":
-		return 1 + 0
-	case "BY":
-		return 2 + 3
-	case "BZ":
-		return 3 + 6
-	case "CX":
-		return 1 + 6
-	case "CY":
-		return 2 + 0
-	case "CZ":
-		return 3 + 3
-	}
-	return -1
-}
-
 func solution(input []byte) int {
 	input = bytes.TrimSpace(input)
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
-	lines := strings.Split(strings.TrimSpace(string(input)), "\n")
-	log.Printf("read %d input lines", len(lines))
-	var score int
-	for _, line := range lines {
-		if o := outcome(line); o < 0 {
-			panic(line)
-		} else {
-			score += o
-		}
+	rounds, err := rps.AoCGuide(rps.MeansThrow).Parse(bytes.NewReader(input))
+	if err != nil {
+		log.WithError(err).Fatal("could not parse input")
+	}
+	log.Printf("read %d input lines", len(rounds))
+	tally := rps.NewTally(rps.RockPaperScissors, rps.AoCScore)
+	for _, round := range rounds {
+		tally.Add(round)
 	}
-	return score
+	return tally.Total
 }
 
 func main() {
@@ -3,116 +3,27 @@ package main
 import (
 	"bytes"
 	"fmt"
-	"image/color"
 	"image/gif"
 	"os"
 	"strconv"
-	"strings"
 
 	"github.com/asymmetricia/aoc22/aoc"
 	"github.com/asymmetricia/aoc22/canvas"
+	"github.com/asymmetricia/aoc22/rps"
 	"github.com/asymmetricia/aoc22/term"
 	"github.com/sirupsen/logrus"
 )
 
-type Throw int
-
-const (
-	Rock     Throw = 1
-	Paper          = 2
-	Scissors       = 3
-)
-
-func (t Throw) String() string {
-	switch t {
-	case Rock:
-		return "rock"
-	case Paper:
-		return "paper"
-	case Scissors:
-		return "scissors"
-	}
-	return "(invalid)"
-}
-
-type Outcome int
-
-const (
-	Lose Outcome = 0
-	Draw         = 3
-	Win          = 6
-)
-
-func (o Outcome) String() string {
-	switch o {
-	case Lose:
-		return "lose"
-	case Draw:
-		return "draw"
-	case Win:
-		return "win"
-	}
-	return "(invalid)"
-}
-
-type Game map[Outcome]uint
-type World map[Throw]Game
-
-func (w World) Record(o Outcome, t Throw) {
-	if _, ok := w[t]; ok {
-		w[t][o]++
-	} else {
-		w[t] = Game{o: 1}
-	}
-}
-
-func (w World) Score() uint {
-	var ret uint
-	for t, game := range w {
-		for o, count := range game {
-			ret += (uint(t) + uint(o)) * count
-		}
-	}
-	return ret
-}
-
-func frame(w World, o Outcome, t Throw, n int, total int, final bool) *canvas.Canvas {
+func frame(tally *rps.Tally, round rps.Round, n int, total int, final bool) *canvas.Canvas {
 	var ret = &canvas.Canvas{}
-	for y, outcome := range []Outcome{Lose, Draw, Win} {
-		for x, throw := range []Throw{Rock, Paper, Scissors} {
-			frameColor := aoc.TolVibrantRed
-			if outcome == Draw {
-				frameColor = aoc.TolVibrantOrange
-			} else if outcome == Win {
-				frameColor = aoc.TolVibrantTeal
-			}
-			var bodyColor color.Color = aoc.TolVibrantMagenta
-			if throw == Paper {
-				bodyColor = color.White
-			}
-			if throw == Scissors {
-				bodyColor = aoc.TolVibrantCyan
-			}
-			amt := w[throw][outcome]
-			canvas.TextBox{
-				Top:        y * (aoc.LineHeight),
-				Left:       x * (aoc.GlyphWidth*3 + 2),
-				Title:      []rune(throw.String()),
-				Body:       []rune(fmt.Sprintf("%3d", amt)),
-				BodyBlock:  true,
-				Footer:     []rune(outcome.String()),
-				FrameColor: frameColor,
-				BodyColor:  bodyColor,
-			}.On(ret)
-		}
-	}
+	tally.Panel(ret, 0, 0)
 
 	if final {
 		canvas.TextBox{
 			Top:       3 * aoc.LineHeight,
 			Center:    true,
 			Title:     []rune("Final Score"),
-			Body:      []rune(strconv.Itoa(int(w.Score()))),
+			Body:      []rune(strconv.Itoa(tally.Total)),
 			BodyBlock: true,
 		}.On(ret)
 	} else {
@@ -120,14 +31,14 @@ func frame(w World, o Outcome, t Throw, n int, total int, final bool) *canvas.Ca
 			Top:     3 * aoc.LineHeight,
 			Center:  true,
 			Footer:  []rune(fmt.Sprintf("game %d of %d", n, total)),
-			Body:    []rune(fmt.Sprintf("Throw %8s in order to %4s", t.String(), o.String())),
+			Body:    []rune(fmt.Sprintf("Throw %8s in order to %4s", tally.Game.Name(round.Mine), round.Outcome)),
 			BodyPad: true,
 		}.On(ret)
 		canvas.TextBox{
 			Top:    3*aoc.LineHeight + 5,
 			Center: true,
 			Title:  []rune("Score"),
-			Body:   []rune(fmt.Sprintf(" %5d ", w.Score())),
+			Body:   []rune(fmt.Sprintf(" %5d ", tally.Total)),
 		}.On(ret)
 	}
 	return ret
@@ -135,56 +46,32 @@ func frame(w World, o Outcome, t Throw, n int, total int, final bool) *canvas.Ca
 
 var log = logrus.StandardLogger()
 
-func outcome(game string) (Outcome, Throw) {
-	switch game {
-	case "A X":
-		return Lose, Scissors
-	case "A Y":
-		return Draw, Rock
-	case "A Z":
-		return Win, Paper
-	case "B X":
-		return Lose, Rock
-	case "B Y":
-		return Draw, Paper
-	case "B Z":
-		return Win, Scissors
-	case "C X":
-		return Lose, Paper
-	case "C Y":
-		return Draw, Scissors
-	case "C Z":
-		return Win, Rock
-	}
-	panic("bad game instructions " + game)
-}
-
 func solution(inputName string, input []byte) int {
 	input = bytes.TrimSpace(input)
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
-	lines := strings.Split(strings.TrimSpace(string(input)), "\n")
-	log.Printf("read %d input lines", len(lines))
-	var score int
-	world := World{}
+	rounds, err := rps.AoCGuide(rps.MeansOutcome).Parse(bytes.NewReader(input))
+	if err != nil {
+		log.WithError(err).Fatal("could not parse input")
+	}
+	log.Printf("read %d input lines", len(rounds))
+	tally := rps.NewTally(rps.RockPaperScissors, rps.AoCScore)
 
 	var frames []*canvas.Canvas
-	for i, line := range lines {
-		outcome, throw := outcome(line)
-		world.Record(outcome, throw)
-		score += int(outcome) + int(throw)
+	for i, round := range rounds {
+		tally.Add(round)
 		if i < 100 ||
 			i >= 100 && i < 200 && i%2 == 0 ||
 			i >= 200 && i < 300 && i%4 == 0 ||
 			i >= 300 && i < 400 && i%8 == 0 ||
 			i >= 400 && i < 1000 && i%11 == 0 ||
 			i >= 1000 && i%47 == 0 {
-			frames = append(frames, frame(world, outcome, throw, i, len(lines), false))
+			frames = append(frames, frame(tally, round, i, len(rounds), false))
 		}
 	}
 
 	// doubled last frames improve the experience when converting to video
-	frames = append(frames, frame(world, 0, 0, 0, 0, true))
-	frames = append(frames, frame(world, 0, 0, 0, 0, true))
+	frames = append(frames, frame(tally, rps.Round{}, 0, 0, true))
+	frames = append(frames, frame(tally, rps.Round{}, 0, 0, true))
 
 	anim := &gif.GIF{}
 	delay := 100
@@ -206,7 +93,7 @@ func solution(inputName string, input []byte) int {
 
 	aoc.SaveGIF(anim, fmt.Sprintf("day2b-%s.gif", inputName), log)
 
-	return score
+	return tally.Total
 }
 
 func main() {
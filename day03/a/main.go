@@ -18,6 +18,15 @@ func prio(r rune) int {
 	return int(r - 'A' + 27)
 }
 
+// items returns the set of priorities of the items in a rucksack.
+func items(s string) set.BitSet {
+	var ret set.BitSet
+	for _, r := range s {
+		ret = ret.Set(prio(r))
+	}
+	return ret
+}
+
 func solution(input []byte) int {
 	input = bytes.TrimSpace(input)
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
@@ -30,11 +39,10 @@ func solution(input []byte) int {
 		first := line[:len(line)/2]
 		second := line[len(line)/2:]
 
-		fset := set.FromString(first)
-		sset := set.FromString(second)
-		for dupe := range fset.Intersect(sset) {
-			sum += prio(dupe)
-		}
+		items(first).Intersect(items(second)).Iter(func(dupe int) bool {
+			sum += dupe
+			return false
+		})
 	}
 
 	return sum
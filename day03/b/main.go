@@ -18,6 +18,15 @@ func prio(r rune) int {
 	return int(r - 'A' + 27)
 }
 
+// items returns the set of priorities of the items in a rucksack.
+func items(s string) set.BitSet {
+	var ret set.BitSet
+	for _, r := range s {
+		ret = ret.Set(prio(r))
+	}
+	return ret
+}
+
 func solution(input []byte) int {
 	input = bytes.TrimSpace(input)
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
@@ -27,13 +36,13 @@ func solution(input []byte) int {
 	var sum = 0
 
 	for i := 0; i < len(lines); i += 3 {
-		a := set.FromString(lines[i])
-		b := set.FromString(lines[i+1])
-		c := set.FromString(lines[i+2])
-		badge := a.Intersect(b).Intersect(c)
-		for badge := range badge {
-			sum += prio(badge)
-		}
+		a := items(lines[i])
+		b := items(lines[i+1])
+		c := items(lines[i+2])
+		a.Intersect(b).Intersect(c).Iter(func(badge int) bool {
+			sum += badge
+			return false
+		})
 	}
 
 	return sum
@@ -2,78 +2,32 @@ package main
 
 import (
 	"bytes"
-	"fmt"
 	"os"
-	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 
-	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/crates"
 )
 
 var log = logrus.StandardLogger()
 
-func move(stacks [][]byte, n, from, to int) [][]byte {
-	if n > len(stacks[from]) {
-		log.Fatalf("cannot move %d crates from stack %d %v", n, from, stacks[from])
-	}
-	for i := 0; i < n; i++ {
-		stacks[to] = append(stacks[to], stacks[from][len(stacks[from])-1])
-		stacks[from] = stacks[from][:len(stacks[from])-1]
-	}
-	return stacks
-}
-
 func solution(name string, input []byte) string {
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
-	lines := strings.Split(string(input), "\n")
-	log.Printf("read %d input lines", len(lines))
+	log.Printf("read %d input lines", bytes.Count(input, []byte("\n")))
 
-	stacks := make([][]byte, 9)
-	state := 0
-	for lineno, line := range lines {
-		switch state {
-		case 0:
-			if line == "" {
-				state++
-				continue
-			}
-			for i := 0; i < len(line); i += 4 {
-				if line[i] == '[' {
-					stacks[i/4] = append([]byte{line[i+1]}, stacks[i/4]...)
-				}
-			}
-
-		case 1:
-			if line == "" {
-				state++
-				continue
-			}
-			re := regexp.MustCompile(`move (\d+) from (\d+) to (\d+)`)
-			matches := re.FindStringSubmatch(line)
-			if matches == nil {
-				panic(strconv.Itoa(lineno) + ": " + line)
-			}
-			n, from, to := aoc.Int(matches[1]), aoc.Int(matches[2])-1, aoc.Int(matches[3])-1
-			stacks = move(stacks, n, from, to)
-		}
+	yard, moves, err := crates.Parse(bytes.NewReader(input))
+	if err != nil {
+		log.WithError(err).Fatal("could not parse input")
 	}
-
-	ans := ""
-	for n, stack := range stacks {
-		if len(stack) > 0 {
-			ans += string(stack[len(stack)-1])
-		}
-		m := fmt.Sprintf("%d: ", n+1)
-		for _, crate := range stack {
-			m += string(crate) + " "
-		}
-		log.Print(m)
+	if err := yard.Run(crates.CrateMover9000, moves); err != nil {
+		log.WithError(err).Fatal("could not rearrange crates")
+	}
+	for _, line := range strings.Split(strings.TrimRight(yard.String(), "\n"), "\n") {
+		log.Print(line)
 	}
 
-	return ans
+	return yard.Tops()
 }
 
 func main() {
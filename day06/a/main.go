@@ -7,22 +7,12 @@ import (
 	"unicode"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/asymmetricia/aoc22/aoc"
 )
 
 var log = logrus.StandardLogger()
 
-func isMarker(s string) bool {
-	if len(s) != 4 {
-		panic(s)
-	}
-	if s[0] == s[1] || s[0] == s[2] || s[0] == s[3] ||
-		s[1] == s[2] || s[1] == s[3] ||
-		s[2] == s[3] {
-		return false
-	}
-	return true
-}
-
 func solution(name string, input []byte) int {
 	// trim trailing space only
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
@@ -30,14 +20,7 @@ func solution(name string, input []byte) int {
 	lines := strings.Split(strings.TrimRightFunc(string(input), unicode.IsSpace), "\n")
 	log.Printf("read %d %s lines", len(lines), name)
 
-	for i := 0; i < len(input); i++ {
-		if !isMarker(string(input[i : i+4])) {
-			continue
-		}
-		log.Printf("%d, %s", i+4, input[i:i+4])
-		break
-	}
-	return -1
+	return aoc.FirstDistinctRun(input, 4)
 }
 
 func main() {
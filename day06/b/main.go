@@ -86,20 +86,6 @@ outer:
 	return ret
 }
 
-func isMarker(s string) bool {
-	if len(s) != 14 {
-		panic(s)
-	}
-	seen := map[byte]bool{}
-	for _, c := range []byte(s) {
-		if seen[c] {
-			return false
-		}
-		seen[c] = true
-	}
-	return true
-}
-
 func solution(name string, input []byte) int {
 	// trim trailing space only
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
@@ -108,8 +94,11 @@ func solution(name string, input []byte) int {
 	log.Printf("read %d %s lines", len(lines), name)
 
 	var frames []*canvas.Canvas
-	for i := 0; i < len(input); i++ {
-		match := isMarker(string(input[i : i+14]))
+	marker := -1
+	window := aoc.NewSlidingWindow(input, 14)
+	for window.Next() {
+		i := window.Start()
+		match := window.AllDistinct()
 		if match || i < 10 ||
 			i < 100 && i%2 == 0 ||
 			i < 200 && i%3 == 0 ||
@@ -122,7 +111,8 @@ func solution(name string, input []byte) int {
 			continue
 		}
 
-		log.Printf("%d, %s", i+14, input[i:i+14])
+		log.Printf("%d, %s", window.End(), window.Window())
+		marker = window.End()
 		break
 	}
 
@@ -141,7 +131,7 @@ func solution(name string, input []byte) int {
 
 	aoc.SaveGIF(anim, "day06b-"+name+".gif", log)
 
-	return -1
+	return marker
 }
 
 func main() {
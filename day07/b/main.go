@@ -2,10 +2,7 @@ package main
 
 import (
 	"bytes"
-	"math"
 	"os"
-	"sort"
-	"strconv"
 	"strings"
 	"unicode"
 
@@ -13,53 +10,45 @@ import (
 
 	"github.com/asymmetricia/aoc22/aoc"
 	"github.com/asymmetricia/aoc22/canvas"
+	"github.com/asymmetricia/aoc22/fstree"
 )
 
 var log = logrus.StandardLogger()
 
-type Directory struct {
-	Files       map[string]int
-	Directories map[string]*Directory
-}
-
-func (d *Directory) DirectoryNames() []string {
-	if d == nil || d.Directories == nil {
-		return nil
-	}
+func dirNames(d *fstree.Node) []string {
 	var ret []string
-	for dir := range d.Directories {
-		ret = append(ret, dir)
+	for _, c := range d.Children() {
+		if c.Dir {
+			ret = append(ret, c.Name)
+		}
 	}
-	sort.Strings(ret)
 	return ret
 }
 
-func (d *Directory) FileNames() []string {
-	if d == nil || d.Files == nil {
-		return nil
-	}
+func fileNames(d *fstree.Node) []string {
 	var ret []string
-	for file := range d.Files {
-		ret = append(ret, file)
+	for _, c := range d.Children() {
+		if !c.Dir {
+			ret = append(ret, c.Name)
+		}
 	}
-	sort.Strings(ret)
 	return ret
 }
 
-func parseFrame(line string, dir string, root *Directory) *canvas.Canvas {
+func parseFrame(line string, dir string, root *fstree.Node) *canvas.Canvas {
 	ret := &canvas.Canvas{}
 	x := 0
 	y := 3
-	col := func(dir *Directory, name string) (width int) {
+	col := func(dir *fstree.Node, name string) (width int) {
 		var body string
-		for _, dirName := range dir.DirectoryNames() {
+		for _, dirName := range dirNames(dir) {
 			dirName += "/"
 			body += dirName + "\n"
 			if len(dirName) > width {
 				width = len(dirName)
 			}
 		}
-		for _, file := range dir.FileNames() {
+		for _, file := range fileNames(dir) {
 			body += file + "\n"
 			if len(file) >= width {
 				width = len(file)
@@ -84,11 +73,11 @@ func parseFrame(line string, dir string, root *Directory) *canvas.Canvas {
 		if path[0] == "" {
 			break
 		}
-		dir, ok := cursor.Directories[path[0]]
-		if !ok {
+		dir := cursor.Child(path[0])
+		if dir == nil {
 			break
 		}
-		for yy, dirname := range cursor.DirectoryNames() {
+		for yy, dirname := range dirNames(cursor) {
 			if dirname == path[0] {
 				y += yy + 1
 				ret.PrintAt(x+1, y, dirname+"/", aoc.TolVibrantMagenta)
@@ -113,66 +102,6 @@ func parseFrame(line string, dir string, root *Directory) *canvas.Canvas {
 	return ret
 }
 
-func (d *Directory) Dir(path string) *Directory {
-	if len(path) == 0 {
-		return d
-	}
-	parts := strings.Split(strings.TrimLeft(path, "/"), "/")
-	if dir, ok := d.Directories[parts[0]]; ok {
-		return dir.Dir(strings.Join(parts[1:], "/"))
-	}
-	return nil
-}
-
-func (d *Directory) Size() int {
-	size := 0
-	for _, filesize := range d.Files {
-		size += filesize
-	}
-	for _, subdir := range d.Directories {
-		size += subdir.Size()
-	}
-	return size
-}
-
-func (d *Directory) WithSizeAbove(path string, n int) map[string]bool {
-	if len(d.Directories) == 0 {
-		return nil
-	}
-
-	ret := map[string]bool{}
-
-	for dirName, dir := range d.Directories {
-		if dir.Size() > n {
-			ret[path+"/"+dirName] = true
-		}
-		for above := range dir.WithSizeAbove(path+"/"+dirName, n) {
-			ret[above] = true
-		}
-	}
-
-	return ret
-}
-
-func (d *Directory) WithSizeAtMost(path string, n int) map[string]bool {
-	if len(d.Directories) == 0 {
-		return nil
-	}
-
-	ret := map[string]bool{}
-
-	for dirName, dir := range d.Directories {
-		if dir.Size() <= n {
-			ret[path+"/"+dirName] = true
-		}
-		for above := range dir.WithSizeAtMost(path+"/"+dirName, n) {
-			ret[above] = true
-		}
-	}
-
-	return ret
-}
-
 func solution(name string, input []byte) int {
 	// trim trailing space only
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
@@ -180,61 +109,48 @@ func solution(name string, input []byte) int {
 	lines := strings.Split(strings.TrimRightFunc(string(input), unicode.IsSpace), "\n")
 	log.Printf("read %d %s lines", len(lines), name)
 
-	var dir string
-	root := &Directory{
-		Files:       map[string]int{},
-		Directories: map[string]*Directory{},
-	}
-
+	sh := fstree.NewShell()
 	var frames []*canvas.Canvas
 	for _, line := range lines {
 		log.Print(line)
-		fields := strings.Fields(line)
-		if fields[0] == "$" && fields[1] == "cd" {
-			if fields[2] == "/" {
-				dir = ""
-			} else if fields[2] == ".." {
-				path := strings.Split(dir, "/")
-				dir = strings.Join(path[:len(path)-1], "/")
-			} else {
-				dir += "/" + fields[2]
-			}
-		} else if fields[0] == "$" && fields[1] == "ls" {
-		} else if fields[0] == "dir" {
-			root.Dir(dir).Directories[fields[1]] = &Directory{
-				Files:       map[string]int{},
-				Directories: map[string]*Directory{},
-			}
-		} else {
-			var err error
-			root.Dir(dir).Files[fields[1]], err = strconv.Atoi(fields[0])
-			if err != nil {
-				panic(line)
-			}
+		if err := sh.Exec(line); err != nil {
+			log.WithError(err).Fatal("could not parse transcript")
 		}
-
-		frames = append(frames, parseFrame(line, dir, root))
+		frame := parseFrame(line, sh.Cwd.Path(), sh.Root)
+		// start slow, then speed up
+		switch i := len(frames); {
+		case i < 10:
+			frame.Timing = 30
+		case i < 20:
+			frame.Timing = 20
+		case i < 30:
+			frame.Timing = 10
+		case i < 100:
+			frame.Timing = 3
+		default:
+			frame.Timing = 1.0 / 2
+		}
+		frames = append(frames, frame)
 	}
+	canvas.RenderGif(frames, "day07b-"+name+".gif", log)
 
-	canvas.RenderGif(
-		frames,
-		map[int]float32{10: 30, 20: 20, 30: 10, 100: 3, math.MaxInt: 1.0 / 2},
-		"day07b-"+name+".gif",
-		log,
-	)
+	treemap, err := fstree.Frames(strings.NewReader(strings.Join(lines, "\n")), 80, 30)
+	if err != nil {
+		log.WithError(err).Fatal("could not parse transcript")
+	}
+	canvas.RenderGif(treemap, "day07b-treemap-"+name+".gif", log)
 
 	const (
 		total        = 70000000
 		neededUnused = 30000000
 	)
-	used := root.Size()
-	bestSize := math.MaxInt
-	for dir := range root.WithSizeAbove("", neededUnused-(total-used)) {
-		if root.Dir(dir).Size() < bestSize {
-			bestSize = root.Dir(dir).Size()
-		}
+	root := sh.Root
+	need := neededUnused - (total - root.Size())
+	dir := root.SmallestDirAtLeast(need)
+	if dir == nil {
+		log.Fatalf("no directory frees up at least %d", need)
 	}
-	return bestSize
+	return dir.Size()
 }
 
 func main() {
@@ -7,6 +7,8 @@ import (
 	"unicode"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/asymmetricia/aoc22/coord"
 )
 
 var log = logrus.StandardLogger()
@@ -26,59 +28,7 @@ func solution(name string, input []byte) int {
 		}
 	}
 
-	visible := map[int]map[int]bool{}
-
-	for y, row := range trees {
-		visible[y] = map[int]bool{}
-		visible[y][0] = true
-		last := row[0]
-		for x, height := range row {
-			if height > last {
-				last = height
-				visible[y][x] = true
-			}
-		}
-
-		visible[y][len(row)-1] = true
-		last = row[len(row)-1]
-		for x := len(row) - 1; x >= 0; x-- {
-			if row[x] > last {
-				last = row[x]
-				visible[y][x] = true
-			}
-		}
-	}
-
-	for x := 0; x < len(trees[0]); x++ {
-		visible[0][x] = true
-		last := trees[0][x]
-		for y, row := range trees {
-			if row[x] > last {
-				last = row[x]
-				visible[y][x] = true
-			}
-		}
-
-		visible[len(trees)-1][x] = true
-		last = trees[len(trees)-1][x]
-		for y := len(trees) - 1; y >= 0; y-- {
-			if trees[y][x] > last {
-				last = trees[y][x]
-				visible[y][x] = true
-			}
-		}
-	}
-
-	count := 0
-	for _, row := range visible {
-		for _, tree := range row {
-			if tree {
-				count++
-			}
-		}
-	}
-
-	return count
+	return coord.Sight(trees).CountVisible(coord.CardinalDirections)
 }
 
 func main() {
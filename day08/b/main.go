@@ -19,42 +19,6 @@ import (
 
 var log = logrus.StandardLogger()
 
-func visible(trees [][]int, x, y int) map[coord.Direction][]coord.Coord {
-	ret := map[coord.Direction][]coord.Coord{}
-
-	height := trees[y][x]
-
-	for north := y - 1; north >= 0; north-- {
-		ret[coord.North] = append(ret[coord.North], coord.C(x, north))
-		if trees[north][x] >= height {
-			break
-		}
-	}
-
-	for south := y + 1; south < len(trees); south++ {
-		ret[coord.South] = append(ret[coord.South], coord.C(x, south))
-		if trees[south][x] >= height {
-			break
-		}
-	}
-
-	for west := x - 1; west >= 0; west-- {
-		ret[coord.West] = append(ret[coord.West], coord.C(west, y))
-		if trees[y][west] >= height {
-			break
-		}
-	}
-
-	for east := x + 1; east < len(trees[0]); east++ {
-		ret[coord.East] = append(ret[coord.East], coord.C(east, y))
-		if trees[y][east] >= height {
-			break
-		}
-	}
-
-	return ret
-}
-
 func frame(
 	state string,
 	trees [][]int,
@@ -232,47 +196,24 @@ func frame(
 	return ret
 }
 
-func perimeterScan(trees [][]int) ([]*canvas.Canvas, map[int]map[int]bool) {
+// perimeterScan replays the visibility from outside the grid as a scan: first
+// each row from the west and east, then each column from the north and south.
+func perimeterScan(trees [][]int, sight *coord.Sightlines) ([]*canvas.Canvas, map[int]map[int]bool) {
 	var ret []*canvas.Canvas
 	visible := map[int]map[int]bool{}
 	for y, row := range trees {
 		visible[y] = map[int]bool{}
-		visible[y][0] = true
-		last := row[0]
-		for x, height := range row {
-			if height > last {
-				last = height
-				visible[y][x] = true
-			}
-		}
-
-		visible[y][len(row)-1] = true
-		last = row[len(row)-1]
-		for x := len(row) - 1; x >= 0; x-- {
-			if row[x] > last {
-				last = row[x]
+		for x := range row {
+			if sight.VisibleFrom(coord.C(x, y), []coord.Direction{coord.West, coord.East}) {
 				visible[y][x] = true
 			}
 		}
-
 		ret = append(ret, frame("perimeter", trees, visible, -1, y, -1, -1, -1))
 	}
 
-	for x := 0; x < len(trees[0]); x++ {
-		visible[0][x] = true
-		last := trees[0][x]
-		for y, row := range trees {
-			if row[x] > last {
-				last = row[x]
-				visible[y][x] = true
-			}
-		}
-
-		visible[len(trees)-1][x] = true
-		last = trees[len(trees)-1][x]
-		for y := len(trees) - 1; y >= 0; y-- {
-			if trees[y][x] > last {
-				last = trees[y][x]
+	for x := 0; x < sight.Width; x++ {
+		for y := range trees {
+			if sight.VisibleFrom(coord.C(x, y), []coord.Direction{coord.North, coord.South}) {
 				visible[y][x] = true
 			}
 		}
@@ -300,18 +241,15 @@ func solution(name string, input []byte) int {
 		frames = append(frames, frame("loading", trees, nil, -1, -1, -1, -1, -1))
 	}
 
-	perimFrames, perimTrees := perimeterScan(trees)
+	sight := coord.Sight(trees)
+	perimFrames, perimTrees := perimeterScan(trees, sight)
 	frames = append(frames, perimFrames...)
 
-	var best coord.Coord
+	scores := sight.Scores(coord.CardinalDirections)
+	best := coord.C(-1, -1)
 	var bestScore int = math.MinInt
-	for y, row := range trees {
-		for x := range row {
-			visibleTrees := visible(trees, x, y)
-			score := len(visibleTrees[coord.North]) *
-				len(visibleTrees[coord.East]) *
-				len(visibleTrees[coord.South]) *
-				len(visibleTrees[coord.West])
+	for y, row := range scores {
+		for x, score := range row {
 			if score > bestScore {
 				best = coord.C(x, y)
 				bestScore = score
@@ -329,7 +267,12 @@ func solution(name string, input []byte) int {
 
 	log.Print("computation finished")
 
-	canvas.RenderGif(frames, nil, "day08b-"+name+".gif", log)
+	canvas.RenderGif(frames, "day08b-"+name+".gif", log)
+
+	heatmap := canvas.Heatmap(scores, func(x, y, _ int) rune {
+		return rune('0' + trees[y][x])
+	})
+	canvas.RenderGif([]*canvas.Canvas{heatmap}, "day08b-scores-"+name+".gif", log)
 
 	return bestScore
 }
@@ -3,99 +3,30 @@ package main
 import (
 	"bytes"
 	"os"
-	"strconv"
-	"strings"
-	"unicode"
 
 	"github.com/sirupsen/logrus"
 
-	"github.com/asymmetricia/aoc22/aoc"
-	"github.com/asymmetricia/aoc22/canvas"
 	"github.com/asymmetricia/aoc22/coord"
+	"github.com/asymmetricia/aoc22/rope"
 )
 
 var log = logrus.StandardLogger()
 
-func moveTail(head, tail coord.Coord) coord.Coord {
-	dx := head.X - tail.X
-	dy := head.Y - tail.Y
-	// horiz
-	if dy == 0 {
-		if dx == 2 {
-			return tail.East()
-		} else if dx == -2 {
-			return tail.West()
-		}
-		return tail
-	}
-	// vert
-	if dx == 0 {
-		if dy == 2 {
-			return tail.South()
-		} else if dy == -2 {
-			return tail.North()
-		}
-		return tail
-	}
-
-	if dx == 2 || dy == 2 || dx == -2 || dy == -2 {
-		if dx > 0 {
-			tail = tail.East()
-		} else {
-			tail = tail.West()
-		}
-		if dy > 0 {
-			tail = tail.South()
-		} else {
-			tail = tail.North()
-		}
-		return tail
-	}
-	return tail
-}
 func solution(name string, input []byte) int {
-	// trim trailing space only
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
-	input = bytes.TrimRightFunc(input, unicode.IsSpace)
-	lines := strings.Split(strings.TrimRightFunc(string(input), unicode.IsSpace), "\n")
-	log.Printf("read %d %s lines", len(lines), name)
-
-	var head, tail coord.Coord = coord.C(10, 10), coord.C(10, 10)
-	positions := map[coord.Coord]bool{
-		tail: true,
+	motions, err := rope.Parse(bytes.NewReader(input))
+	if err != nil {
+		log.WithError(err).Fatal("could not parse input")
 	}
+	log.Printf("read %d %s motions", len(motions), name)
 
-	for _, line := range lines {
-		fields := strings.Fields(line)
-		c, err := strconv.Atoi(fields[1])
-		if err != nil {
-			panic(line)
-		}
-
-		for c > 0 {
-			c--
-			switch fields[0] {
-			case "U":
-				head = head.North()
-			case "D":
-				head = head.South()
-			case "L":
-				head = head.West()
-			case "R":
-				head = head.East()
-			}
-			tail = moveTail(head, tail)
-			positions[tail] = true
-			c := canvas.Canvas{}
-			if name == "test" {
-				c.PrintAt(head.X, head.Y, "H", aoc.TolVibrantGrey)
-				c.PrintAt(tail.X, tail.Y, "T", aoc.TolVibrantGrey)
-				println(c.String())
-			}
-		}
+	r := rope.New(2, coord.C(0, 0), rope.Chebyshev)
+	r.Apply(motions...)
+	if name == "test" {
+		println(r.Canvas(r.Bounds()).String())
 	}
 
-	return len(positions)
+	return len(r.TailVisited())
 }
 
 func main() {
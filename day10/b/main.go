@@ -108,7 +108,9 @@ func solution(name string, input []byte) int {
 		}
 	}
 
+	rows := make([][]bool, 6)
 	for y := 0; y < 6; y++ {
+		rows[y] = cpu.FrameBuffer[y*40 : (y+1)*40]
 		for x := 0; x < 40; x++ {
 			if cpu.FrameBuffer[y*40+x] {
 				print("#")
@@ -118,8 +120,13 @@ func solution(name string, input []byte) int {
 		}
 		println()
 	}
+	if text, err := aoc.OCR(rows); err != nil {
+		log.WithError(err).Warning("could not read the display")
+	} else {
+		log.Printf("%s display reads %q", name, text)
+	}
 
-	canvas.RenderGif(cpu.Frames, nil, "day10-"+name+".gif", log)
+	canvas.RenderGif(cpu.Frames, "day10-"+name+".gif", log)
 
 	return cpu.SS
 }
@@ -14,6 +14,7 @@ import (
 	"golang.org/x/exp/slices"
 
 	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/set"
 )
 
 var log = logrus.StandardLogger()
@@ -141,10 +142,10 @@ func solution(name string, input []byte) int {
 		network[name] = &Valve{Rate: rate, Peers: peers, Neighbors: map[string]*Valve{}}
 	}
 
-	valveId := map[string]int{}
+	// only valves worth opening get an index, so the masks fit in a BitSet
+	var valveId set.Indexer[string]
 	valves := map[string]bool{}
 	for id, valve := range network {
-		valveId[id] = len(valveId) + 1
 		if valve.Rate > 0 {
 			valves[id] = true
 		}
@@ -174,18 +175,14 @@ func solution(name string, input []byte) int {
 	type path struct {
 		path   []string
 		value  int
-		valves int64
+		valves set.BitSet
 	}
 	var ourPaths []path
 	for i := 1; i <= 15; i++ {
 		p := Explore("AA", network, valves, 26, i)
 		for _, p := range p {
 			valves, v := simulate(network, p)
-			var vb int64
-			for valve := range valves {
-				vb |= 1 << valveId[valve]
-			}
-			ourPaths = append(ourPaths, path{p, v, vb})
+			ourPaths = append(ourPaths, path{p, v, valveId.BitSet(maps.Keys(valves)...)})
 		}
 	}
 
@@ -196,7 +193,7 @@ func solution(name string, input []byte) int {
 			last = time.Now()
 		}
 		for _, pair := range ourPaths[i+1:] {
-			if path.valves&pair.valves > 0 {
+			if path.valves.Intersect(pair.valves) != 0 {
 				continue
 			}
 			if path.value+pair.value > best {
@@ -9,47 +9,11 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/numbase"
 )
 
 var log = logrus.StandardLogger()
 
-func ToSnafu(i int) string {
-	if i == 0 {
-		return ""
-	}
-
-	switch i % 5 {
-	case 0:
-		return ToSnafu(i/5) + "0"
-	case 1:
-		return ToSnafu(i/5) + "1"
-	case 2:
-		return ToSnafu(i/5) + "2"
-	case 3:
-		return ToSnafu(i/5+1) + "="
-	default:
-		return ToSnafu(i/5+1) + "-"
-	}
-}
-
-func ParseSnafu(s string) int {
-	var ret int
-	for i, c := range s {
-		n := map[rune]int{
-			'2': 2,
-			'1': 1,
-			'0': 0,
-			'-': -1,
-			'=': -2,
-		}[c]
-		for j := 0; j < len(s)-i-1; j++ {
-			n *= 5
-		}
-		ret += n
-	}
-	return ret
-}
-
 func solution(name string, input []byte) int {
 	// trim trailing space only
 	input = bytes.Replace(input, []byte("\r"), []byte(""), -1)
@@ -61,12 +25,16 @@ func solution(name string, input []byte) int {
 	}
 	log.Printf("read %d %s lines (%d unique)", len(lines), name, len(uniq))
 
-	sum := 0
+	sum := "0"
 	for _, line := range lines {
-		sum += ParseSnafu(line)
+		var err error
+		sum, err = numbase.Snafu.Add(sum, line)
+		if err != nil {
+			log.WithError(err).Fatal("bad SNAFU number")
+		}
 	}
 
-	log.Print(ToSnafu(sum))
+	log.Print(sum)
 	return -1
 }
 
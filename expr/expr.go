@@ -0,0 +1,376 @@
+// Package expr evaluates and solves arithmetic expression DAGs made of named
+// nodes, like AoC 2022 day 21's monkeys. Arithmetic is exact, using big.Rat.
+package expr
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+type Op int
+
+const (
+	Const Op = iota
+	Var
+	Add
+	Sub
+	Mul
+	Div
+)
+
+func (o Op) String() string {
+	switch o {
+	case Const:
+		return "const"
+	case Var:
+		return "var"
+	case Add:
+		return "+"
+	case Sub:
+		return "-"
+	case Mul:
+		return "*"
+	case Div:
+		return "/"
+	}
+	return fmt.Sprintf("(bad op %d)", int(o))
+}
+
+var ops = map[string]Op{"+": Add, "-": Sub, "*": Mul, "/": Div}
+
+var (
+	ErrUnknown      = errors.New("unknown node")
+	ErrCycle        = errors.New("cycle")
+	ErrDivideByZero = errors.New("division by zero")
+	ErrUnbound      = errors.New("variable has no value")
+	ErrNonLinear    = errors.New("expression is not linear in the variable")
+	ErrNoSolution   = errors.New("no unique solution")
+)
+
+// Node is a named node in the graph. Const nodes have a Value; Var nodes have
+// none; the rest combine the nodes named Left and Right.
+type Node struct {
+	Name        string
+	Op          Op
+	Value       *big.Rat
+	Left, Right string
+}
+
+// Graph is a set of nodes, referring to each other by name. Evaluation results
+// are memoized until the graph is changed with Set, SetConst or SetVar.
+type Graph struct {
+	nodes map[string]*Node
+	memo  map[string]*big.Rat
+}
+
+func New() *Graph {
+	return &Graph{nodes: map[string]*Node{}}
+}
+
+// Parse reads nodes in the form "name: 5" or "name: left op right", one per
+// line, where op is one of + - * /.
+func Parse(r io.Reader) (*Graph, error) {
+	g := New()
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected name: expression, got %q", lineNo, line)
+		}
+		fields := strings.Fields(rest)
+		switch len(fields) {
+		case 1:
+			v, ok := new(big.Rat).SetString(fields[0])
+			if !ok {
+				return nil, fmt.Errorf("line %d: bad constant %q", lineNo, fields[0])
+			}
+			g.Set(&Node{Name: name, Op: Const, Value: v})
+		case 3:
+			op, ok := ops[fields[1]]
+			if !ok {
+				return nil, fmt.Errorf("line %d: bad operator %q", lineNo, fields[1])
+			}
+			g.Set(&Node{Name: name, Op: op, Left: fields[0], Right: fields[2]})
+		default:
+			return nil, fmt.Errorf("line %d: expected constant or binary expression, got %q", lineNo, rest)
+		}
+	}
+	return g, scanner.Err()
+}
+
+// ParseString is Parse for a string.
+func ParseString(s string) (*Graph, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// Set adds or replaces a node. The graph keeps n, so n must not be modified
+// afterwards; Set it again with a new node instead.
+func (g *Graph) Set(n *Node) {
+	g.nodes[n.Name] = n
+	g.memo = nil
+}
+
+// Get returns a copy of the named node, and whether there is one.
+func (g *Graph) Get(name string) (Node, bool) {
+	n, ok := g.nodes[name]
+	if !ok {
+		return Node{}, false
+	}
+	return *n, true
+}
+
+// Names returns the names of all the nodes, sorted.
+func (g *Graph) Names() []string {
+	names := make([]string, 0, len(g.nodes))
+	for name := range g.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetConst makes name a constant with the given value.
+func (g *Graph) SetConst(name string, v *big.Rat) {
+	g.Set(&Node{Name: name, Op: Const, Value: v})
+}
+
+// SetVar makes name an unknown, to be found with Solve.
+func (g *Graph) SetVar(name string) {
+	g.Set(&Node{Name: name, Op: Var})
+}
+
+// Eval returns the value of the named node. The result must not be modified.
+func (g *Graph) Eval(name string) (*big.Rat, error) {
+	if g.memo == nil {
+		g.memo = map[string]*big.Rat{}
+	}
+	return g.eval(name, map[string]bool{})
+}
+
+func (g *Graph) eval(name string, visiting map[string]bool) (*big.Rat, error) {
+	if v, ok := g.memo[name]; ok {
+		return v, nil
+	}
+	n, ok := g.nodes[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknown, name)
+	}
+	if visiting[name] {
+		return nil, fmt.Errorf("%w at %q", ErrCycle, name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	var ret *big.Rat
+	switch n.Op {
+	case Const:
+		ret = n.Value
+	case Var:
+		return nil, fmt.Errorf("%w: %q", ErrUnbound, name)
+	default:
+		l, err := g.eval(n.Left, visiting)
+		if err != nil {
+			return nil, err
+		}
+		r, err := g.eval(n.Right, visiting)
+		if err != nil {
+			return nil, err
+		}
+		ret, err = apply(n.Op, l, r)
+		if err != nil {
+			return nil, fmt.Errorf("at %q: %w", name, err)
+		}
+	}
+	g.memo[name] = ret
+	return ret, nil
+}
+
+func apply(op Op, l, r *big.Rat) (*big.Rat, error) {
+	ret := new(big.Rat)
+	switch op {
+	case Add:
+		ret.Add(l, r)
+	case Sub:
+		ret.Sub(l, r)
+	case Mul:
+		ret.Mul(l, r)
+	case Div:
+		if r.Sign() == 0 {
+			return nil, ErrDivideByZero
+		}
+		ret.Quo(l, r)
+	default:
+		return nil, fmt.Errorf("bad op %v", op)
+	}
+	return ret, nil
+}
+
+// linear is the expression A*x + B.
+type linear struct {
+	A, B *big.Rat
+}
+
+// collapse reduces the named node to a linear function of the single Var node
+// x. Variables other than x are an error.
+func (g *Graph) collapse(name, x string, memo map[string]linear, visiting map[string]bool) (linear, error) {
+	if l, ok := memo[name]; ok {
+		return l, nil
+	}
+	n, ok := g.nodes[name]
+	if !ok {
+		return linear{}, fmt.Errorf("%w %q", ErrUnknown, name)
+	}
+	if visiting[name] {
+		return linear{}, fmt.Errorf("%w at %q", ErrCycle, name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	zero := new(big.Rat)
+	var ret linear
+	switch n.Op {
+	case Const:
+		ret = linear{zero, n.Value}
+	case Var:
+		if name != x {
+			return linear{}, fmt.Errorf("%w: %q", ErrUnbound, name)
+		}
+		ret = linear{big.NewRat(1, 1), zero}
+	default:
+		l, err := g.collapse(n.Left, x, memo, visiting)
+		if err != nil {
+			return linear{}, err
+		}
+		r, err := g.collapse(n.Right, x, memo, visiting)
+		if err != nil {
+			return linear{}, err
+		}
+		switch n.Op {
+		case Add:
+			ret = linear{new(big.Rat).Add(l.A, r.A), new(big.Rat).Add(l.B, r.B)}
+		case Sub:
+			ret = linear{new(big.Rat).Sub(l.A, r.A), new(big.Rat).Sub(l.B, r.B)}
+		case Mul:
+			if l.A.Sign() != 0 && r.A.Sign() != 0 {
+				return linear{}, fmt.Errorf("at %q: %w", name, ErrNonLinear)
+			}
+			// (a x + b)(c x + d) = (ad + bc) x + bd, given ac = 0
+			ad := new(big.Rat).Mul(l.A, r.B)
+			bc := new(big.Rat).Mul(l.B, r.A)
+			ret = linear{ad.Add(ad, bc), new(big.Rat).Mul(l.B, r.B)}
+		case Div:
+			if r.A.Sign() != 0 {
+				return linear{}, fmt.Errorf("at %q: %w", name, ErrNonLinear)
+			}
+			if r.B.Sign() == 0 {
+				return linear{}, fmt.Errorf("at %q: %w", name, ErrDivideByZero)
+			}
+			ret = linear{new(big.Rat).Quo(l.A, r.B), new(big.Rat).Quo(l.B, r.B)}
+		}
+	}
+	memo[name] = ret
+	return ret, nil
+}
+
+// Solve finds the value of the Var node x that makes nodes a and b equal. x
+// may appear any number of times on either side, as long as both sides are
+// linear in x.
+func (g *Graph) Solve(a, b, x string) (*big.Rat, error) {
+	if n, ok := g.nodes[x]; !ok || n.Op != Var {
+		return nil, fmt.Errorf("%q is not a variable", x)
+	}
+	memo := map[string]linear{}
+	l, err := g.collapse(a, x, memo, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	r, err := g.collapse(b, x, memo, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	// l.A x + l.B = r.A x + r.B  =>  x = (r.B - l.B) / (l.A - r.A)
+	den := new(big.Rat).Sub(l.A, r.A)
+	if den.Sign() == 0 {
+		return nil, ErrNoSolution
+	}
+	num := new(big.Rat).Sub(r.B, l.B)
+	return num.Quo(num, den), nil
+}
+
+// Expr renders the named node as a fully-parenthesized infix expression. Var
+// nodes and unknown names are rendered as their names.
+func (g *Graph) Expr(name string) string {
+	sb := &strings.Builder{}
+	g.write(sb, name, map[string]bool{})
+	return sb.String()
+}
+
+func (g *Graph) write(sb *strings.Builder, name string, visiting map[string]bool) {
+	n, ok := g.nodes[name]
+	if !ok || n.Op == Var || visiting[name] {
+		sb.WriteString(name)
+		return
+	}
+	if n.Op == Const {
+		sb.WriteString(ratString(n.Value))
+		return
+	}
+	visiting[name] = true
+	sb.WriteByte('(')
+	g.write(sb, n.Left, visiting)
+	sb.WriteString(" " + n.Op.String() + " ")
+	g.write(sb, n.Right, visiting)
+	sb.WriteByte(')')
+	delete(visiting, name)
+}
+
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	return r.String()
+}
+
+// DOT renders the graph in Graphviz DOT format, with edges from each node to
+// its operands.
+func (g *Graph) DOT() string {
+	names := g.Names()
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("digraph expr {\n")
+	for _, name := range names {
+		n := g.nodes[name]
+		label := n.Op.String()
+		shape := "ellipse"
+		switch n.Op {
+		case Const:
+			label = ratString(n.Value)
+			shape = "box"
+		case Var:
+			label = "?"
+			shape = "diamond"
+		}
+		fmt.Fprintf(buf, "\t%q [label=%q shape=%s];\n", name, name+"\n"+label, shape)
+	}
+	for _, name := range names {
+		n := g.nodes[name]
+		if n.Op == Const || n.Op == Var {
+			continue
+		}
+		fmt.Fprintf(buf, "\t%q -> %q [label=\"l\"];\n", name, n.Left)
+		fmt.Fprintf(buf, "\t%q -> %q [label=\"r\"];\n", name, n.Right)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
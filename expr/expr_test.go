@@ -0,0 +1,93 @@
+package expr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const monkeys = `root: pppw + sjmn
+dbpl: 5
+cczh: sllz + lgvd
+zczc: 2
+ptdq: humn - dvpt
+dvpt: 3
+lfqf: 4
+humn: 5
+ljgn: 2
+sjmn: drzm * dbpl
+sllz: 4
+pppw: cczh / lfqf
+lgvd: ljgn * ptdq
+drzm: hmdt - zczc
+hmdt: 32
+`
+
+func TestGraph_Monkeys(t *testing.T) {
+	g, err := ParseString(monkeys)
+	require.NoError(t, err)
+
+	root, err := g.Eval("root")
+	require.NoError(t, err)
+	require.Equal(t, "152", ratString(root))
+
+	g.SetVar("humn")
+	_, err = g.Eval("root")
+	require.ErrorIs(t, err, ErrUnbound)
+
+	eq, ok := g.Get("root")
+	require.True(t, ok)
+	x, err := g.Solve(eq.Left, eq.Right, "humn")
+	require.NoError(t, err)
+	require.Equal(t, "301", ratString(x))
+
+	require.Equal(t, "((4 + (2 * (humn - 3))) / 4)", g.Expr("pppw"))
+	require.Contains(t, g.DOT(), "\t\"pppw\" -> \"cczh\" [label=\"l\"];\n")
+}
+
+func TestGraph_Solve(t *testing.T) {
+	g, err := ParseString(`
+a: x * three
+b: x + seven
+c: a - b
+d: c / two
+e: x * x
+three: 3
+seven: 7
+two: 2
+half: 1/2
+`)
+	require.NoError(t, err)
+	g.SetVar("x")
+
+	// x appears on both sides: (3x - (x + 7)) / 2 = x + 7 simplifies to
+	// 2x - 7 = 2x + 14, which has no solution
+	_, err = g.Solve("d", "b", "x")
+	require.ErrorIs(t, err, ErrNoSolution)
+
+	// (2x - 7) / 2 = 1/2  =>  x = 4
+	x, err := g.Solve("d", "half", "x")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(4, 1), x)
+
+	// 3x = x + 7  =>  x = 7/2, exactly
+	x, err = g.Solve("a", "b", "x")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(7, 2), x)
+
+	_, err = g.Solve("e", "seven", "x")
+	require.ErrorIs(t, err, ErrNonLinear)
+
+	g.Set(&Node{Name: "loop", Op: Add, Left: "loop", Right: "two"})
+	_, err = g.Eval("loop")
+	require.ErrorIs(t, err, ErrCycle)
+
+	g.Set(&Node{Name: "zero", Op: Sub, Left: "two", Right: "two"})
+	g.Set(&Node{Name: "bad", Op: Div, Left: "two", Right: "zero"})
+	_, err = g.Eval("bad")
+	require.ErrorIs(t, err, ErrDivideByZero)
+
+	_, err = g.Eval("nope")
+	require.ErrorIs(t, err, ErrUnknown)
+}
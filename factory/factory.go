@@ -0,0 +1,233 @@
+// Package factory plans the robot factories of AoC 2022 day 19: robots that
+// each produce one unit of a resource per minute, and a factory that spends
+// resources to build one more robot per minute. A Planner finds the build
+// schedule that ends with the most geodes.
+package factory
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Resource is something robots produce, and the kind of robot that produces
+// it.
+type Resource int
+
+const (
+	Ore Resource = iota
+	Clay
+	Obsidian
+	Geode
+
+	// NumResources is the number of kinds of resource.
+	NumResources = 4
+)
+
+// Resources are the names of the resources, indexed by Resource.
+var Resources = [NumResources]string{"ore", "clay", "obsidian", "geode"}
+
+func (r Resource) String() string {
+	if r < 0 || r >= NumResources {
+		return fmt.Sprintf("(bad resource %d)", int(r))
+	}
+	return Resources[r]
+}
+
+// ParseResource returns the resource with the given name.
+func ParseResource(s string) (Resource, error) {
+	for r, name := range Resources {
+		if s == name {
+			return Resource(r), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown resource %q", s)
+}
+
+// Amounts is a quantity of each resource, or a number of each kind of robot.
+type Amounts [NumResources]int
+
+// Covers reports whether a has at least as much of every resource as b.
+func (a Amounts) Covers(b Amounts) bool {
+	for r := range a {
+		if a[r] < b[r] {
+			return false
+		}
+	}
+	return true
+}
+
+func (a Amounts) Plus(b Amounts) Amounts {
+	for r := range a {
+		a[r] += b[r]
+	}
+	return a
+}
+
+func (a Amounts) Minus(b Amounts) Amounts {
+	for r := range a {
+		a[r] -= b[r]
+	}
+	return a
+}
+
+// Times returns a with every amount multiplied by n.
+func (a Amounts) Times(n int) Amounts {
+	for r := range a {
+		a[r] *= n
+	}
+	return a
+}
+
+// String lists the non-zero amounts, like "3 ore and 14 clay".
+func (a Amounts) String() string {
+	var parts []string
+	for r, n := range a {
+		if n != 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, Resource(r)))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// Blueprint is the cost of building each kind of robot.
+type Blueprint struct {
+	ID int
+	// Costs[r] is what a robot producing r costs.
+	Costs [NumResources]Amounts
+}
+
+// MaxUse returns, for each resource, the most of it any one robot costs. The
+// factory builds at most one robot a minute, so there's no use in producing
+// more than that per minute.
+func (bp *Blueprint) MaxUse() Amounts {
+	var ret Amounts
+	for _, c := range bp.Costs {
+		for r, n := range c {
+			if n > ret[r] {
+				ret[r] = n
+			}
+		}
+	}
+	return ret
+}
+
+func (bp *Blueprint) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Blueprint %d:", bp.ID)
+	for r, c := range bp.Costs {
+		fmt.Fprintf(&b, " Each %s robot costs %s.", Resource(r), c)
+	}
+	return b.String()
+}
+
+var ErrMissingRobot = errors.New("blueprint is missing a robot")
+
+// Parse reads blueprints like "Blueprint 1: Each ore robot costs 4 ore. Each
+// clay robot costs 2 ore. ...", either one per line or, as in the puzzle's
+// example, with each sentence on its own line. Every blueprint must give the
+// cost of every kind of robot.
+func Parse(r io.Reader) ([]Blueprint, error) {
+	var ret []Blueprint
+	// seen tracks which robots the current blueprint has costs for
+	var seen [NumResources]bool
+	var start int
+	finish := func() error {
+		if len(ret) == 0 {
+			return nil
+		}
+		for r, ok := range seen {
+			if !ok {
+				return fmt.Errorf("line %d: %w: no cost for %s robot", start, ErrMissingRobot, Resource(r))
+			}
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "Blueprint ") {
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			key, rest, ok := strings.Cut(line, ":")
+			id, err := strconv.Atoi(strings.TrimPrefix(key, "Blueprint "))
+			if !ok || err != nil {
+				return nil, fmt.Errorf("line %d: expected Blueprint <id>:, got %q", lineNo, line)
+			}
+			ret = append(ret, Blueprint{ID: id})
+			seen = [NumResources]bool{}
+			start = lineNo
+			line = rest
+		} else if len(ret) == 0 {
+			return nil, fmt.Errorf("line %d: %q before any blueprint", lineNo, line)
+		}
+
+		bp := &ret[len(ret)-1]
+		for _, sentence := range strings.Split(line, ".") {
+			sentence = strings.TrimSpace(sentence)
+			if sentence == "" {
+				continue
+			}
+			robot, cost, err := parseSentence(sentence)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			if seen[robot] {
+				return nil, fmt.Errorf("line %d: second cost for %s robot", lineNo, robot)
+			}
+			seen[robot] = true
+			bp.Costs[robot] = cost
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ParseString is Parse for a string.
+func ParseString(s string) ([]Blueprint, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// parseSentence reads "Each <resource> robot costs <n> <resource> and ...".
+func parseSentence(s string) (Resource, Amounts, error) {
+	f := strings.Fields(s)
+	if len(f) < 6 || f[0] != "Each" || f[2] != "robot" || f[3] != "costs" {
+		return 0, Amounts{}, fmt.Errorf("expected Each <resource> robot costs ..., got %q", s)
+	}
+	robot, err := ParseResource(f[1])
+	if err != nil {
+		return 0, Amounts{}, err
+	}
+
+	var cost Amounts
+	for i, part := range strings.Split(strings.Join(f[4:], " "), " and ") {
+		n, name, ok := strings.Cut(part, " ")
+		count, err := strconv.Atoi(n)
+		if !ok || err != nil || count < 0 {
+			return 0, Amounts{}, fmt.Errorf("bad cost %d %q in %q", i+1, part, s)
+		}
+		r, err := ParseResource(name)
+		if err != nil {
+			return 0, Amounts{}, fmt.Errorf("%w in %q", err, s)
+		}
+		cost[r] += count
+	}
+	return robot, cost, nil
+}
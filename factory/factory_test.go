@@ -0,0 +1,148 @@
+package factory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const example = `Blueprint 1:
+  Each ore robot costs 4 ore.
+  Each clay robot costs 2 ore.
+  Each obsidian robot costs 3 ore and 14 clay.
+  Each geode robot costs 2 ore and 7 obsidian.
+
+Blueprint 2: Each ore robot costs 2 ore. Each clay robot costs 3 ore. Each obsidian robot costs 3 ore and 8 clay. Each geode robot costs 3 ore and 12 obsidian.
+`
+
+func TestParse(t *testing.T) {
+	bps, err := ParseString(example)
+	require.NoError(t, err)
+	require.Len(t, bps, 2)
+	require.Equal(t, Blueprint{ID: 1, Costs: [NumResources]Amounts{
+		Ore:      {Ore: 4},
+		Clay:     {Ore: 2},
+		Obsidian: {Ore: 3, Clay: 14},
+		Geode:    {Ore: 2, Obsidian: 7},
+	}}, bps[0])
+	require.Equal(t, Amounts{Ore: 3, Clay: 8, Obsidian: 12}, bps[1].MaxUse())
+
+	// String round-trips
+	again, err := ParseString(bps[1].String())
+	require.NoError(t, err)
+	require.Equal(t, bps[1:], again)
+
+	_, err = ParseString("Blueprint 1: Each ore robot costs 4 ore.")
+	require.True(t, errors.Is(err, ErrMissingRobot))
+
+	for _, bad := range []string{
+		"Each ore robot costs 4 ore.",
+		"Blueprint x: Each ore robot costs 4 ore.",
+		"Blueprint 1: Each ore robot costs 4 diamond.",
+		"Blueprint 1: Each ore robot costs four ore.",
+		"Blueprint 1: Each ore robot costs 4 ore. Each ore robot costs 3 ore.",
+	} {
+		_, err := ParseString(bad)
+		require.Error(t, err, bad)
+		require.Contains(t, err.Error(), "line 1:")
+	}
+}
+
+func TestPlanner(t *testing.T) {
+	bps, err := ParseString(example)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		bounds  []Bound
+		minutes int
+		want    []int
+	}{
+		{"default", nil, 24, []int{9, 12}},
+		{"optimistic", []Bound{Optimistic}, 24, []int{9, 12}},
+		{"relaxed", []Bound{Relaxed}, 24, []int{9, 12}},
+		{"exhaustive", []Bound{}, 24, []int{9, 12}},
+		{"default 32", nil, 32, []int{56, 62}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Planner{Bounds: tt.bounds}
+			plans := p.PlanAll(bps, tt.minutes)
+			for i, plan := range plans {
+				require.Equal(t, tt.want[i], plan.Geodes)
+				require.Equal(t, bps[i].ID, plan.Blueprint.ID)
+
+				// the schedule really does produce that many geodes
+				states, err := plan.Replay()
+				require.NoError(t, err)
+				require.Len(t, states, tt.minutes+1)
+				require.Equal(t, plan.Geodes, states[tt.minutes].Stock[Geode])
+			}
+		})
+	}
+
+	plans := (&Planner{Workers: 1}).PlanAll(bps, 24)
+	require.Equal(t, 33, plans[0].Quality()+plans[1].Quality())
+
+	// Relaxed is tighter than Optimistic, so explores less on its own
+	opt := (&Planner{Bounds: []Bound{Optimistic}}).Plan(bps[0], 24)
+	rel := (&Planner{Bounds: []Bound{Relaxed}}).Plan(bps[0], 24)
+	require.Less(t, rel.Explored, opt.Explored)
+}
+
+// TestBounds checks that the bounds are never below what the best plan from a
+// state actually gets.
+func TestBounds(t *testing.T) {
+	bps, err := ParseString(example)
+	require.NoError(t, err)
+	for _, bp := range bps {
+		plan := (&Planner{}).Plan(bp, 24)
+		states, err := plan.Replay()
+		require.NoError(t, err)
+		for _, s := range states {
+			require.GreaterOrEqual(t, Optimistic(&bp, s, 24), plan.Geodes, "%+v", s)
+			require.GreaterOrEqual(t, Relaxed(&bp, s, 24), plan.Geodes, "%+v", s)
+		}
+	}
+}
+
+func TestPlan_Replay(t *testing.T) {
+	bps, err := ParseString(example)
+	require.NoError(t, err)
+
+	// the schedule from the puzzle's walkthrough
+	plan := Plan{Blueprint: bps[0], Minutes: 24, Builds: []Build{
+		{3, Clay}, {5, Clay}, {7, Clay}, {11, Obsidian}, {12, Clay},
+		{15, Obsidian}, {18, Geode}, {21, Geode},
+	}}
+	states, err := plan.Replay()
+	require.NoError(t, err)
+	require.Equal(t, State{Minute: 24, Robots: Amounts{1, 4, 2, 2}, Stock: Amounts{6, 41, 8, 9}}, states[24])
+
+	plan.Builds = []Build{{1, Clay}}
+	_, err = plan.Replay()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "minute 1:")
+
+	plan.Builds = []Build{{3, Clay}, {3, Clay}}
+	_, err = plan.Replay()
+	require.Error(t, err)
+}
+
+func TestPlan_Timeline(t *testing.T) {
+	bps, err := ParseString(example)
+	require.NoError(t, err)
+	plan := (&Planner{}).Plan(bps[0], 24)
+	cnv := plan.Timeline()
+	require.Equal(t, 9+3*24, cnv.Rect().Dx())
+	row := func(y int) string {
+		var ret []rune
+		for _, c := range cnv.Pix[y] {
+			ret = append(ret, c.Value)
+		}
+		return string(ret)
+	}
+	require.Equal(t, "ore", row(4)[:3])
+	require.Equal(t, "  9", row(8)[len(row(8))-3:])
+}
@@ -0,0 +1,274 @@
+package factory
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// State is a factory at the end of a minute.
+type State struct {
+	// Minute is how many minutes have passed.
+	Minute int
+	Robots Amounts
+	Stock  Amounts
+}
+
+// Start is the state before the first minute: one ore robot and nothing else.
+var Start = State{Robots: Amounts{Ore: 1}}
+
+// Build is a robot built as part of a plan.
+type Build struct {
+	// Minute is the minute, counting from 1, during which the robot is built.
+	// It starts producing the minute after.
+	Minute int
+	Robot  Resource
+}
+
+// Plan is a build schedule for a blueprint.
+type Plan struct {
+	Blueprint Blueprint
+	Minutes   int
+	// Geodes is how many geodes the plan ends with.
+	Geodes int
+	// Builds are the robots to build, in order. The factory waits in every
+	// other minute.
+	Builds []Build
+	// Explored is how many states the planner visited to find the plan.
+	Explored int
+}
+
+// Quality is the blueprint's quality level: its ID times the geodes.
+func (p *Plan) Quality() int {
+	return p.Blueprint.ID * p.Geodes
+}
+
+func (p *Plan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "blueprint %d: %d geodes in %d minutes", p.Blueprint.ID, p.Geodes, p.Minutes)
+	for i, build := range p.Builds {
+		sep := "; "
+		if i == 0 {
+			sep = ": "
+		}
+		fmt.Fprintf(&b, "%s%d %s", sep, build.Minute, build.Robot)
+	}
+	return b.String()
+}
+
+// Replay runs the plan from Start and returns the state at the end of each
+// minute, with Start first, or an error if a build can't be afforded or two
+// share a minute.
+func (p *Plan) Replay() ([]State, error) {
+	states := []State{Start}
+	s := Start
+	builds := p.Builds
+	for s.Minute < p.Minutes {
+		var built Amounts
+		if len(builds) > 0 && builds[0].Minute == s.Minute+1 {
+			robot := builds[0].Robot
+			cost := p.Blueprint.Costs[robot]
+			if !s.Stock.Covers(cost) {
+				return nil, fmt.Errorf("minute %d: %s robot costs %s, have %s", s.Minute+1, robot, cost, s.Stock)
+			}
+			s.Stock = s.Stock.Minus(cost)
+			built[robot]++
+			builds = builds[1:]
+		}
+		s.Stock = s.Stock.Plus(s.Robots)
+		s.Robots = s.Robots.Plus(built)
+		s.Minute++
+		states = append(states, s)
+	}
+	if len(builds) > 0 {
+		return nil, fmt.Errorf("build at minute %d is out of order or past the end", builds[0].Minute)
+	}
+	return states, nil
+}
+
+// Bound returns an upper bound on the geodes that any plan continuing from s
+// can have at the end of minute minutes. It must never be too low, or the
+// planner will miss the best plan; the tighter it is, the fewer states the
+// planner explores.
+type Bound func(bp *Blueprint, s State, minutes int) int
+
+// Optimistic bounds the geodes by supposing a geode robot is built every
+// remaining minute, starting with this one if it's affordable and the next
+// otherwise.
+func Optimistic(bp *Blueprint, s State, minutes int) int {
+	t := minutes - s.Minute
+	ret := s.Stock[Geode] + s.Robots[Geode]*t
+	if !s.Stock.Covers(bp.Costs[Geode]) {
+		t--
+	}
+	if t > 0 {
+		ret += t * (t - 1) / 2
+	}
+	return ret
+}
+
+// Relaxed bounds the geodes by relaxing the problem: each kind of robot gets
+// its own copy of the stock, which all robots produce into but only robots of
+// that kind are paid from, and the factory can build one of every kind each
+// minute. The relaxed factory can always keep up with the real one, so
+// building every robot as soon as it can gives a bound.
+func Relaxed(bp *Blueprint, s State, minutes int) int {
+	var pools [NumResources]Amounts
+	for r := range pools {
+		pools[r] = s.Stock
+	}
+	robots := s.Robots
+	for m := s.Minute; m < minutes; m++ {
+		var built Amounts
+		for r := range pools {
+			if pools[r].Covers(bp.Costs[r]) {
+				pools[r] = pools[r].Minus(bp.Costs[r])
+				built[r]++
+			}
+		}
+		for r := range pools {
+			pools[r] = pools[r].Plus(robots)
+		}
+		robots = robots.Plus(built)
+	}
+	return pools[Geode][Geode]
+}
+
+// DefaultBounds are the bounds a Planner uses if it isn't given any: the
+// cheap one first, so it can prune before the expensive one runs.
+var DefaultBounds = []Bound{Optimistic, Relaxed}
+
+// Planner finds the best plans for blueprints. It searches depth-first over
+// which robot to build next, skipping straight to the minute it's affordable
+// instead of considering each minute of waiting, and prunes any state that its
+// bounds show can't beat the best plan found so far.
+type Planner struct {
+	// Bounds prune the search; a state is abandoned if any of them is no
+	// better than the best plan so far. Nil means DefaultBounds; an empty,
+	// non-nil slice searches exhaustively.
+	Bounds []Bound
+	// Workers is how many blueprints PlanAll plans at once; 0 means
+	// runtime.GOMAXPROCS(0).
+	Workers int
+}
+
+// Plan returns the plan for bp that ends with the most geodes after minutes.
+func (p *Planner) Plan(bp Blueprint, minutes int) Plan {
+	bounds := p.Bounds
+	if bounds == nil {
+		bounds = DefaultBounds
+	}
+	s := &search{
+		bp:      &bp,
+		minutes: minutes,
+		bounds:  bounds,
+		maxUse:  bp.MaxUse(),
+		best:    Plan{Blueprint: bp, Minutes: minutes, Geodes: -1},
+	}
+	s.visit(Start)
+	s.best.Explored = s.explored
+	return s.best
+}
+
+// PlanAll plans each blueprint in parallel, and returns the plans in the same
+// order.
+func (p *Planner) PlanAll(bps []Blueprint, minutes int) []Plan {
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	ret := make([]Plan, len(bps))
+	todo := make(chan int)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range todo {
+				ret[i] = p.Plan(bps[i], minutes)
+			}
+		}()
+	}
+	for i := range bps {
+		todo <- i
+	}
+	close(todo)
+	wg.Wait()
+	return ret
+}
+
+type search struct {
+	bp       *Blueprint
+	minutes  int
+	bounds   []Bound
+	maxUse   Amounts
+	builds   []Build
+	best     Plan
+	explored int
+}
+
+func (s *search) visit(st State) {
+	s.explored++
+
+	// doing nothing more is always a plan
+	if g := st.Stock[Geode] + st.Robots[Geode]*(s.minutes-st.Minute); g > s.best.Geodes {
+		s.best.Geodes = g
+		s.best.Builds = append([]Build(nil), s.builds...)
+	}
+
+	for _, bound := range s.bounds {
+		if bound(s.bp, st, s.minutes) <= s.best.Geodes {
+			return
+		}
+	}
+
+	// geodes first, so good plans are found early and prune more
+	for robot := Geode; robot >= Ore; robot-- {
+		if robot != Geode && s.enough(st, robot) {
+			continue
+		}
+		wait, ok := waitFor(st, s.bp.Costs[robot])
+		// a robot built in the last minute never produces anything
+		if !ok || st.Minute+wait >= s.minutes-1 {
+			continue
+		}
+		next := st
+		next.Stock = next.Stock.Plus(next.Robots.Times(wait + 1)).Minus(s.bp.Costs[robot])
+		next.Robots[robot]++
+		next.Minute += wait + 1
+
+		s.builds = append(s.builds, Build{Minute: next.Minute, Robot: robot})
+		s.visit(next)
+		s.builds = s.builds[:len(s.builds)-1]
+	}
+}
+
+// enough reports whether st already has all the robot producing r it could
+// use: either one for each unit a build could spend per minute, or enough
+// stock to pay for a build every remaining minute.
+func (s *search) enough(st State, r Resource) bool {
+	t := s.minutes - st.Minute
+	return st.Robots[r] >= s.maxUse[r] ||
+		st.Stock[r]+st.Robots[r]*t >= s.maxUse[r]*t
+}
+
+// waitFor returns how many minutes st must wait before it can afford cost, or
+// false if its robots will never produce enough.
+func waitFor(st State, cost Amounts) (int, bool) {
+	wait := 0
+	for r, need := range cost {
+		short := need - st.Stock[r]
+		if short <= 0 {
+			continue
+		}
+		if st.Robots[r] == 0 {
+			return 0, false
+		}
+		if w := (short + st.Robots[r] - 1) / st.Robots[r]; w > wait {
+			wait = w
+		}
+	}
+	return wait, true
+}
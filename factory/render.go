@@ -0,0 +1,68 @@
+package factory
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/canvas"
+)
+
+// resourceColors color each resource's row of the timeline.
+var resourceColors = [NumResources]color.Color{
+	Ore:      aoc.TolVibrantOrange,
+	Clay:     aoc.TolVibrantRed,
+	Obsidian: aoc.TolVibrantMagenta,
+	Geode:    aoc.TolVibrantCyan,
+}
+
+// Timeline draws the plan as a chart below a box naming the blueprint: a
+// column for each minute, and a row for each kind of robot giving how many
+// are working that minute, colored where a new one has joined them. A last row
+// gives the geodes opened by the end of each minute.
+func (p *Plan) Timeline() *canvas.Canvas {
+	const (
+		labelWidth = 9
+		colWidth   = 3
+		top        = 3
+	)
+	cnv := &canvas.Canvas{}
+
+	states, err := p.Replay()
+	footer := fmt.Sprintf("%d geodes in %d minutes", p.Geodes, p.Minutes)
+	if err != nil {
+		footer = err.Error()
+	}
+	canvas.TextBox{
+		Title:       []rune(fmt.Sprintf("Blueprint %d", p.Blueprint.ID)),
+		Body:        []rune(fmt.Sprintf("%d robots built", len(p.Builds))),
+		Footer:      []rune(footer),
+		TitleColor:  aoc.TolVibrantTeal,
+		FrameColor:  aoc.TolVibrantGrey,
+		FooterColor: aoc.TolVibrantCyan,
+		Width:       aoc.Max(labelWidth+colWidth*p.Minutes-2, len(footer)),
+	}.On(cnv)
+
+	for m := 1; m <= p.Minutes; m++ {
+		cnv.PrintAt(labelWidth+(m-1)*colWidth, top, fmt.Sprintf("%*d", colWidth, m), aoc.TolVibrantGrey)
+	}
+	for r := Resource(0); r < NumResources; r++ {
+		y := top + 1 + int(r)
+		cnv.PrintAt(0, y, r.String(), resourceColors[r])
+		// states[m-1] holds the robots working during minute m
+		for m := 1; m < len(states); m++ {
+			c := color.Color(aoc.TolVibrantGrey)
+			if m > 1 && states[m-1].Robots[r] > states[m-2].Robots[r] {
+				c = resourceColors[r]
+			}
+			cnv.PrintAt(labelWidth+(m-1)*colWidth, y, fmt.Sprintf("%*d", colWidth, states[m-1].Robots[r]), c)
+		}
+	}
+
+	y := top + 1 + NumResources
+	cnv.PrintAt(0, y, "opened", resourceColors[Geode])
+	for m := 1; m < len(states); m++ {
+		cnv.PrintAt(labelWidth+(m-1)*colWidth, y, fmt.Sprintf("%*d", colWidth, states[m].Stock[Geode]), color.White)
+	}
+	return cnv
+}
@@ -0,0 +1,318 @@
+// Package fstree rebuilds a filesystem tree from a terminal transcript of cd
+// and ls commands, like AoC 2022 day 7's, and answers questions about its
+// sizes.
+package fstree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Node is a file or directory. A directory's size is the total size of
+// everything in it; it's kept up to date as the tree grows, so Size is O(1).
+type Node struct {
+	Name   string
+	Parent *Node
+	Dir    bool
+
+	size     int
+	children map[string]*Node
+}
+
+// New returns an empty tree: just the root directory, "/".
+func New() *Node {
+	return &Node{Name: "/", Dir: true, children: map[string]*Node{}}
+}
+
+func (n *Node) Size() int {
+	return n.size
+}
+
+// Path returns the absolute path of n, e.g. "/a/e".
+func (n *Node) Path() string {
+	if n.Parent == nil {
+		return "/"
+	}
+	var parts []string
+	for ; n.Parent != nil; n = n.Parent {
+		parts = append(parts, n.Name)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// Depth returns how many directories contain n; the root's depth is 0.
+func (n *Node) Depth() int {
+	d := 0
+	for ; n.Parent != nil; n = n.Parent {
+		d++
+	}
+	return d
+}
+
+// Child returns the named child of n, or nil.
+func (n *Node) Child(name string) *Node {
+	return n.children[name]
+}
+
+// Children returns the contents of directory n, sorted by name.
+func (n *Node) Children() []*Node {
+	ret := make([]*Node, 0, len(n.children))
+	for _, c := range n.children {
+		ret = append(ret, c)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// Root returns the root of the tree containing n.
+func (n *Node) Root() *Node {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return n
+}
+
+// Lookup returns the node at path, relative to n unless it starts with "/", or
+// nil if there's no such node. "." and ".." work as usual.
+func (n *Node) Lookup(path string) *Node {
+	cur := n
+	if strings.HasPrefix(path, "/") {
+		cur = n.Root()
+	}
+	for _, part := range strings.Split(path, "/") {
+		switch part {
+		case "", ".":
+		case "..":
+			if cur.Parent != nil {
+				cur = cur.Parent
+			}
+		default:
+			cur = cur.children[part]
+			if cur == nil {
+				return nil
+			}
+		}
+	}
+	return cur
+}
+
+// Mkdir returns the named subdirectory of n, creating it if needed.
+func (n *Node) Mkdir(name string) (*Node, error) {
+	if err := n.checkChild(name); err != nil {
+		return nil, err
+	}
+	if c, ok := n.children[name]; ok {
+		if !c.Dir {
+			return nil, fmt.Errorf("%s is a file", c.Path())
+		}
+		return c, nil
+	}
+	c := &Node{Name: name, Parent: n, Dir: true, children: map[string]*Node{}}
+	n.children[name] = c
+	return c, nil
+}
+
+// AddFile adds a file to n, or changes its size if it's already there, and
+// updates the sizes of the directories containing it.
+func (n *Node) AddFile(name string, size int) (*Node, error) {
+	if err := n.checkChild(name); err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("%s: negative size %d", name, size)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &Node{Name: name, Parent: n}
+		n.children[name] = c
+	} else if c.Dir {
+		return nil, fmt.Errorf("%s is a directory", c.Path())
+	}
+
+	delta := size - c.size
+	for p := c; p != nil; p = p.Parent {
+		p.size += delta
+	}
+	return c, nil
+}
+
+func (n *Node) checkChild(name string) error {
+	if !n.Dir {
+		return fmt.Errorf("%s is not a directory", n.Path())
+	}
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return fmt.Errorf("bad name %q", name)
+	}
+	return nil
+}
+
+// Walk calls f for n and everything under it, depth first, parents before
+// children and siblings in name order. If f returns false for a directory,
+// Walk skips its contents.
+func (n *Node) Walk(f func(*Node) bool) {
+	if !f(n) || !n.Dir {
+		return
+	}
+	for _, c := range n.Children() {
+		c.Walk(f)
+	}
+}
+
+// Filter returns the nodes under n, including n, for which keep returns true,
+// in Walk order.
+func (n *Node) Filter(keep func(*Node) bool) []*Node {
+	var ret []*Node
+	n.Walk(func(c *Node) bool {
+		if keep(c) {
+			ret = append(ret, c)
+		}
+		return true
+	})
+	return ret
+}
+
+// DirsAtMost returns the directories under n, including n, of at most size.
+func (n *Node) DirsAtMost(size int) []*Node {
+	return n.Filter(func(c *Node) bool {
+		return c.Dir && c.size <= size
+	})
+}
+
+// SmallestDirAtLeast returns the smallest directory under n, including n, of
+// at least size, or nil if there's none.
+func (n *Node) SmallestDirAtLeast(size int) *Node {
+	var best *Node
+	n.Walk(func(c *Node) bool {
+		if !c.Dir || c.size < size {
+			// nothing inside can be big enough either
+			return false
+		}
+		if best == nil || c.size < best.size {
+			best = c
+		}
+		return true
+	})
+	return best
+}
+
+// Du lists the directories under n, including n, like du(1): each line holds a
+// size and a path, with everything in a directory listed before it. If all is
+// true, files are listed too.
+func (n *Node) Du(all bool) string {
+	sb := &strings.Builder{}
+	n.du(sb, all)
+	return sb.String()
+}
+
+func (n *Node) du(sb *strings.Builder, all bool) {
+	if n.Dir {
+		for _, c := range n.Children() {
+			c.du(sb, all)
+		}
+	} else if !all {
+		return
+	}
+	fmt.Fprintf(sb, "%d\t%s\n", n.size, n.Path())
+}
+
+// Shell replays a transcript one line at a time, building the tree as it goes.
+type Shell struct {
+	Root *Node
+	// Cwd is the current directory.
+	Cwd *Node
+
+	line    int
+	listing bool
+}
+
+// NewShell returns a shell in the root of an empty tree.
+func NewShell() *Shell {
+	root := New()
+	return &Shell{Root: root, Cwd: root}
+}
+
+// Exec processes one line of the transcript: a command ("$ cd dir" or "$ ls")
+// or a line of ls output ("dir name" or "size name"). Blank lines are ignored.
+func (s *Shell) Exec(line string) error {
+	s.line++
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if fields[0] == "$" {
+		s.listing = false
+		switch {
+		case len(fields) == 2 && fields[1] == "ls":
+			s.listing = true
+		case len(fields) == 3 && fields[1] == "cd":
+			switch fields[2] {
+			case "/":
+				s.Cwd = s.Root
+			case "..":
+				if s.Cwd.Parent == nil {
+					return fmt.Errorf("line %d: cd .. from /", s.line)
+				}
+				s.Cwd = s.Cwd.Parent
+			default:
+				// ls usually comes first, but a cd into an unlisted directory
+				// tells us it exists
+				dir, err := s.Cwd.Mkdir(fields[2])
+				if err != nil {
+					return fmt.Errorf("line %d: cd: %w", s.line, err)
+				}
+				s.Cwd = dir
+			}
+		default:
+			return fmt.Errorf("line %d: unknown command %q", s.line, line)
+		}
+		return nil
+	}
+
+	if !s.listing {
+		return fmt.Errorf("line %d: output %q without ls", s.line, line)
+	}
+	if len(fields) != 2 {
+		return fmt.Errorf("line %d: bad ls output %q", s.line, line)
+	}
+	if fields[0] == "dir" {
+		if _, err := s.Cwd.Mkdir(fields[1]); err != nil {
+			return fmt.Errorf("line %d: %w", s.line, err)
+		}
+		return nil
+	}
+	size, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return fmt.Errorf("line %d: bad size %q", s.line, fields[0])
+	}
+	if _, err := s.Cwd.AddFile(fields[1], size); err != nil {
+		return fmt.Errorf("line %d: %w", s.line, err)
+	}
+	return nil
+}
+
+// Parse replays the transcript and returns the root of the resulting tree.
+func Parse(r io.Reader) (*Node, error) {
+	sh := NewShell()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if err := sh.Exec(scanner.Text()); err != nil {
+			return nil, err
+		}
+	}
+	return sh.Root, scanner.Err()
+}
+
+// ParseString is Parse for a string.
+func ParseString(s string) (*Node, error) {
+	return Parse(strings.NewReader(s))
+}
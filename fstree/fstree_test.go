@@ -0,0 +1,155 @@
+package fstree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/asymmetricia/aoc22/canvas"
+)
+
+const example = `$ cd /
+$ ls
+dir a
+14848514 b.txt
+8504156 c.dat
+dir d
+$ cd a
+$ ls
+dir e
+29116 f
+2557 g
+62596 h.lst
+$ cd e
+$ ls
+584 i
+$ cd ..
+$ cd ..
+$ cd d
+$ ls
+4060174 j
+8033020 d.log
+5626152 d.ext
+7214296 k
+`
+
+func TestParse(t *testing.T) {
+	root, err := ParseString(example)
+	require.NoError(t, err)
+
+	sizes := map[string]int{
+		"/":       48381165,
+		"/a":      94853,
+		"/a/e":    584,
+		"/d":      24933642,
+		"/a/e/i":  584,
+		"/b.txt":  14848514,
+		"a/h.lst": 62596,
+	}
+	for path, size := range sizes {
+		n := root.Lookup(path)
+		require.NotNil(t, n, path)
+		require.Equal(t, size, n.Size(), path)
+	}
+	require.Nil(t, root.Lookup("/nope"))
+	require.Equal(t, "/a/e", root.Lookup("/d/../a/./e").Path())
+	require.Equal(t, root, root.Lookup("/a/e").Lookup("/"))
+
+	// part 1
+	sum := 0
+	for _, dir := range root.DirsAtMost(100000) {
+		sum += dir.Size()
+	}
+	require.Equal(t, 95437, sum)
+
+	// part 2
+	need := 30000000 - (70000000 - root.Size())
+	require.Equal(t, "/d", root.SmallestDirAtLeast(need).Path())
+	require.Nil(t, root.SmallestDirAtLeast(root.Size()+1))
+}
+
+func TestNode_Du(t *testing.T) {
+	root, err := ParseString(example)
+	require.NoError(t, err)
+	require.Equal(t, `584	/a/e
+94853	/a
+24933642	/d
+48381165	/
+`, root.Du(false))
+
+	all := strings.Split(strings.TrimSpace(root.Du(true)), "\n")
+	require.Len(t, all, 14)
+	require.Equal(t, "584\t/a/e/i", all[0])
+	require.Equal(t, "14848514\t/b.txt", all[6])
+}
+
+func TestNode_AddFile(t *testing.T) {
+	root := New()
+	a, err := root.Mkdir("a")
+	require.NoError(t, err)
+	_, err = a.AddFile("f", 10)
+	require.NoError(t, err)
+	require.Equal(t, 10, root.Size())
+
+	// listing a file again with a new size replaces it
+	_, err = a.AddFile("f", 4)
+	require.NoError(t, err)
+	require.Equal(t, 4, a.Size())
+	require.Equal(t, 4, root.Size())
+
+	_, err = a.Mkdir("f")
+	require.Error(t, err)
+	_, err = root.AddFile("a", 1)
+	require.Error(t, err)
+	_, err = root.Mkdir("x/y")
+	require.Error(t, err)
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, transcript := range []string{
+		"$ cd ..",
+		"123 x",
+		"$ ls\nbig x",
+		"$ ls\n1 2 3",
+		"$ rm -rf /",
+		"$ ls\n1 x\n$ cd x",
+	} {
+		_, err := ParseString(transcript)
+		require.Error(t, err, transcript)
+	}
+}
+
+func plain(cnv *canvas.Canvas) string {
+	sb := &strings.Builder{}
+	for _, row := range cnv.Pix {
+		for _, c := range row {
+			if c.Value == 0 {
+				sb.WriteRune(' ')
+			} else {
+				sb.WriteRune(c.Value)
+			}
+		}
+		sb.WriteRune('\n')
+	}
+	return sb.String()
+}
+
+func TestFrames(t *testing.T) {
+	frames, err := Frames(strings.NewReader(example), 40, 12)
+	require.NoError(t, err)
+	// one per command, plus the final tree
+	require.Len(t, frames, 11)
+
+	final := plain(frames[len(frames)-1])
+	for _, name := range []string{"a", "d", "b.txt", "k"} {
+		require.Contains(t, final, name)
+	}
+
+	require.Equal(t, `┏/━━━━━━━┓
+┃        ┃
+┃        ┃
+┃        ┃
+┗━━━━━━━━┛
+`, plain(New().Treemap(10, 5)))
+}
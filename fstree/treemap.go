@@ -0,0 +1,170 @@
+package fstree
+
+import (
+	"bufio"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/canvas"
+)
+
+// Treemap draws n as a width by height treemap: every directory is a titled box,
+// and its area is divided among its contents in proportion to their sizes.
+// Files are colored by size, on a log scale.
+func (n *Node) Treemap(width, height int) *canvas.Canvas {
+	cnv := &canvas.Canvas{}
+	n.drawTreemap(cnv, image.Rect(0, 0, width, height), nil)
+	return cnv
+}
+
+func (n *Node) drawTreemap(cnv *canvas.Canvas, r image.Rectangle, highlight *Node) {
+	largest := 1
+	n.Walk(func(c *Node) bool {
+		if !c.Dir && c.size > largest {
+			largest = c.size
+		}
+		return true
+	})
+	tm := treemap{cnv: cnv, largest: largest, highlight: highlight, depth: n.Depth()}
+	tm.draw(n, r)
+}
+
+// depthColors are the frame colors of directories, by depth; red is reserved
+// for the highlighted directory.
+var depthColors = []color.Color{
+	aoc.TolVibrantBlue,
+	aoc.TolVibrantCyan,
+	aoc.TolVibrantTeal,
+	aoc.TolVibrantOrange,
+	aoc.TolVibrantMagenta,
+}
+
+type treemap struct {
+	cnv       *canvas.Canvas
+	largest   int
+	highlight *Node
+	depth     int
+}
+
+func (tm *treemap) draw(n *Node, r image.Rectangle) {
+	if r.Empty() {
+		return
+	}
+
+	if !n.Dir {
+		color := aoc.TolVibrantGrey
+		if tm.largest > 1 {
+			color = aoc.TolScaleLog(1, tm.largest, aoc.Max(n.size, 1))
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				tm.cnv.Set(x, y, canvas.Cell{Color: color, Value: '#'})
+			}
+		}
+		name := []rune(n.Name)
+		if len(name) > r.Dx() {
+			name = name[:r.Dx()]
+		}
+		tm.cnv.PrintAt(r.Min.X, r.Min.Y, string(name), aoc.TolVibrantGrey)
+		return
+	}
+
+	inner := r
+	if r.Dx() >= 3 && r.Dy() >= 3 {
+		frame := depthColors[(n.Depth()-tm.depth)%len(depthColors)]
+		if n == tm.highlight {
+			frame = aoc.TolVibrantRed
+		}
+		canvas.TextBox{
+			Top:        r.Min.Y,
+			Left:       r.Min.X,
+			Title:      []rune(n.Name),
+			FrameColor: frame,
+			TitleColor: aoc.TolVibrantGrey,
+			Width:      r.Dx() - 2,
+			Height:     r.Dy() - 2,
+		}.On(tm.cnv)
+		inner = r.Inset(1)
+	}
+	if n.size == 0 {
+		return
+	}
+
+	var children []*Node
+	for _, c := range n.children {
+		if c.size > 0 {
+			children = append(children, c)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].size != children[j].size {
+			return children[i].size > children[j].size
+		}
+		return children[i].Name < children[j].Name
+	})
+
+	// slice along the longer side; cells are taller than they are wide
+	horizontal := inner.Dx()*aoc.GlyphWidth >= inner.Dy()*aoc.LineHeight
+	length := inner.Dy()
+	if horizontal {
+		length = inner.Dx()
+	}
+	cum := 0
+	for _, c := range children {
+		start := int(math.Round(float64(cum) * float64(length) / float64(n.size)))
+		cum += c.size
+		end := int(math.Round(float64(cum) * float64(length) / float64(n.size)))
+		sub := inner
+		if horizontal {
+			sub.Min.X, sub.Max.X = inner.Min.X+start, inner.Min.X+end
+		} else {
+			sub.Min.Y, sub.Max.Y = inner.Min.Y+start, inner.Min.Y+end
+		}
+		tm.draw(c, sub)
+	}
+}
+
+// Frames replays a transcript and draws the tree as it's discovered: a frame
+// for each command, showing the command above a width by height treemap with
+// the current directory picked out, and a final frame of the whole tree. The
+// frames are suitable for canvas.RenderGif.
+func Frames(r io.Reader, width, height int) ([]*canvas.Canvas, error) {
+	sh := NewShell()
+	var frames []*canvas.Canvas
+	frame := func(line string) {
+		cnv := &canvas.Canvas{}
+		canvas.TextBox{
+			Title:      []rune(sh.Cwd.Path()),
+			Body:       []rune(line),
+			BodyColor:  aoc.TolVibrantOrange,
+			TitleColor: aoc.TolVibrantTeal,
+			FrameColor: aoc.TolVibrantGrey,
+			Width:      width - 2,
+		}.On(cnv)
+		sh.Root.drawTreemap(cnv, image.Rect(0, 3, width, 3+height), sh.Cwd)
+		frames = append(frames, cnv)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "$") {
+			frame(line)
+		}
+		if err := sh.Exec(line); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sh.Cwd = sh.Root
+	frame("")
+	return frames, nil
+}
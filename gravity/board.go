@@ -0,0 +1,180 @@
+// Package gravity simulates things falling onto a grid: sand pouring from a
+// source onto rock, like AoC 2022 day 14, and rocks pushed by jets of gas as
+// they fall into a chamber, like day 17. Both run on a Board, which packs each
+// row of the grid into bits.
+package gravity
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Board is a grid of cells, each full or empty, Width columns wide and as tall
+// as needed. Each row is stored as a bitmask, column x in bit x%64 of word
+// x/64, so whole rows of a Piece can be tested and placed at once.
+//
+// Board has no notion of which way is up; rows are numbered from 0 and grow
+// as cells are set.
+type Board struct {
+	width, stride int
+	rows          []uint64
+}
+
+// NewBoard returns an empty board width columns wide.
+func NewBoard(width int) *Board {
+	if width < 1 {
+		panic(fmt.Sprintf("gravity.NewBoard: bad width %d", width))
+	}
+	return &Board{width: width, stride: (width + 63) / 64}
+}
+
+func (b *Board) Width() int {
+	return b.width
+}
+
+// Height returns the number of rows stored, which is at least Top.
+func (b *Board) Height() int {
+	return len(b.rows) / b.stride
+}
+
+// Top returns one more than the highest row with anything in it, or 0 if the
+// board is empty.
+func (b *Board) Top() int {
+	for y := b.Height() - 1; y >= 0; y-- {
+		for _, w := range b.row(y) {
+			if w != 0 {
+				return y + 1
+			}
+		}
+	}
+	return 0
+}
+
+func (b *Board) row(y int) []uint64 {
+	return b.rows[y*b.stride : (y+1)*b.stride]
+}
+
+func (b *Board) grow(height int) {
+	if n := height * b.stride; n > len(b.rows) {
+		b.rows = append(b.rows, make([]uint64, n-len(b.rows))...)
+	}
+}
+
+// Get reports whether the cell at column x of row y is full. Cells off the
+// board are empty.
+func (b *Board) Get(x, y int) bool {
+	if x < 0 || x >= b.width || y < 0 || y >= b.Height() {
+		return false
+	}
+	return b.rows[y*b.stride+x/64]&(1<<(x%64)) != 0
+}
+
+// Set fills the cell at column x of row y, which must be on the board.
+func (b *Board) Set(x, y int) {
+	if x < 0 || x >= b.width || y < 0 {
+		panic(fmt.Sprintf("gravity.Board.Set: (%d,%d) is off the board", x, y))
+	}
+	b.grow(y + 1)
+	b.rows[y*b.stride+x/64] |= 1 << (x % 64)
+}
+
+// Count returns the number of full cells.
+func (b *Board) Count() int {
+	n := 0
+	for _, w := range b.rows {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// shifted returns mask moved x columns right, as the words it covers starting
+// at word x/64.
+func shifted(mask uint64, x int) (lo, hi uint64) {
+	off := x % 64
+	lo = mask << off
+	if off > 0 {
+		hi = mask >> (64 - off)
+	}
+	return lo, hi
+}
+
+// Fits reports whether p can be placed with its bottom-left corner at column x
+// of row y: it must lie entirely on the board and overlap no full cell. Rows
+// above the board are empty, so p fits anywhere up there between the sides.
+func (b *Board) Fits(p *Piece, x, y int) bool {
+	if x < 0 || x+p.Width > b.width || y < 0 {
+		return false
+	}
+	w := x / 64
+	for i, mask := range p.Rows {
+		if y+i >= b.Height() {
+			break
+		}
+		row := b.row(y + i)
+		lo, hi := shifted(mask, x)
+		if row[w]&lo != 0 || hi != 0 && row[w+1]&hi != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Place fills the cells of p with its bottom-left corner at column x of row
+// y, whether or not it fits.
+func (b *Board) Place(p *Piece, x, y int) {
+	if x < 0 || x+p.Width > b.width || y < 0 {
+		panic(fmt.Sprintf("gravity.Board.Place: piece at (%d,%d) is off the board", x, y))
+	}
+	b.grow(y + len(p.Rows))
+	w := x / 64
+	for i, mask := range p.Rows {
+		row := b.row(y + i)
+		lo, hi := shifted(mask, x)
+		row[w] |= lo
+		if hi != 0 {
+			row[w+1] |= hi
+		}
+	}
+}
+
+// Trim removes the bottom n rows, moving the rest down.
+func (b *Board) Trim(n int) {
+	if n <= 0 {
+		return
+	}
+	if n >= b.Height() {
+		b.rows = b.rows[:0]
+		return
+	}
+	k := copy(b.rows, b.rows[n*b.stride:])
+	b.rows = b.rows[:k]
+}
+
+// Copy returns a copy of b.
+func (b *Board) Copy() *Board {
+	ret := *b
+	ret.rows = append([]uint64(nil), b.rows...)
+	return &ret
+}
+
+// String draws b with '#' for full cells and '.' for empty ones, one line per
+// row, starting with row 0.
+func (b *Board) String() string {
+	sb := &strings.Builder{}
+	for y := 0; y < b.Height(); y++ {
+		b.writeRow(sb, y)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func (b *Board) writeRow(sb *strings.Builder, y int) {
+	for x := 0; x < b.width; x++ {
+		if b.Get(x, y) {
+			sb.WriteByte('#')
+		} else {
+			sb.WriteByte('.')
+		}
+	}
+}
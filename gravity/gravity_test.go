@@ -0,0 +1,130 @@
+package gravity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/asymmetricia/aoc22/coord"
+)
+
+const jetExample = ">>><<><>><<<>><>>><<<>>><<<><<<>><>><<>>"
+
+const scanExample = `498,4 -> 498,6 -> 496,6
+503,4 -> 502,4 -> 502,9 -> 494,9
+`
+
+func TestParsePiece(t *testing.T) {
+	p, err := ParsePiece("..#\n..#\n###\n")
+	require.NoError(t, err)
+	require.Equal(t, Piece{Width: 3, Rows: []uint64{0b111, 0b100, 0b100}}, p)
+	require.Equal(t, "..#\n..#\n###\n", p.String())
+
+	require.Len(t, Rocks, 5)
+
+	for _, bad := range []string{"", "...", "#x#", strings.Repeat("#", 65)} {
+		_, err := ParsePiece(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestBoard(t *testing.T) {
+	// wide enough that pieces straddle words
+	b := NewBoard(130)
+	p := Piece{Width: 3, Rows: []uint64{0b111, 0b010}}
+	require.True(t, b.Fits(&p, 62, 0))
+	b.Place(&p, 62, 0)
+	require.Equal(t, 2, b.Top())
+	require.Equal(t, 4, b.Count())
+	for _, x := range []int{62, 63, 64} {
+		require.True(t, b.Get(x, 0), x)
+	}
+	require.True(t, b.Get(63, 1))
+	require.False(t, b.Get(64, 1))
+
+	require.False(t, b.Fits(&p, 60, 0))
+	require.True(t, b.Fits(&p, 59, 0))
+	require.True(t, b.Fits(&p, 63, 2))
+	require.False(t, b.Fits(&p, 128, 0))
+	require.False(t, b.Fits(&p, -1, 0))
+	require.False(t, b.Fits(&p, 0, -1))
+
+	b.Trim(1)
+	require.Equal(t, 1, b.Top())
+	require.True(t, b.Get(63, 0))
+	require.False(t, b.Get(62, 0))
+}
+
+func TestTower(t *testing.T) {
+	jets, err := ParseJets(jetExample)
+	require.NoError(t, err)
+
+	require.Panics(t, func() { NewTower(5, Rocks, jets) }, "the 4-wide rocks don't fit")
+	require.NotPanics(t, func() { NewTower(6, Rocks, jets) })
+
+	tower := NewTower(7, Rocks, jets)
+	tower.Drop()
+	tower.Drop()
+	require.Equal(t, ""+
+		"|...#...|\n"+
+		"|..###..|\n"+
+		"|...#...|\n"+
+		"|..####.|\n"+
+		"+-------+\n", tower.String())
+
+	tests := []struct {
+		n    int64
+		want int64
+	}{
+		{2022, 3068},
+		{1000000000000, 1514285714288},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, NewTower(7, Rocks, jets).Simulate(tt.n), tt.n)
+	}
+
+	// without skipping ahead, the answer is the same
+	tower = NewTower(7, Rocks, jets)
+	for i := 0; i < 2022; i++ {
+		tower.Drop()
+	}
+	require.EqualValues(t, 3068, tower.Height())
+	require.Less(t, tower.Board.Height(), 100)
+
+	_, err = ParseJets("<>x")
+	require.Error(t, err)
+}
+
+func TestSand(t *testing.T) {
+	paths, err := ParseScan(strings.NewReader(scanExample))
+	require.NoError(t, err)
+
+	tests := []struct {
+		floor   bool
+		outcome Outcome
+		want    int
+	}{
+		{false, Overflow, 24},
+		{true, Blocked, 93},
+	}
+	for _, tt := range tests {
+		s, err := NewSand(paths, coord.C(500, 0), tt.floor)
+		require.NoError(t, err)
+		outcome, n := s.Fill()
+		require.Equal(t, tt.outcome, outcome)
+		require.Equal(t, tt.want, n)
+	}
+
+	s, err := NewSand(paths, coord.C(500, 0), false)
+	require.NoError(t, err)
+	var last coord.Coord
+	for i := 0; i < 5; i++ {
+		_, last = s.Drop()
+	}
+	require.Equal(t, coord.C(498, 8), last)
+	require.Contains(t, s.String(), "\n..........o.#........\n........oooo#........\n")
+
+	_, err = NewSand([][]coord.Coord{{coord.C(1, 1), coord.C(2, 2)}}, coord.C(500, 0), false)
+	require.Error(t, err)
+}
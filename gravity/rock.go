@@ -0,0 +1,315 @@
+package gravity
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Piece is a shape that falls as a unit. Rows holds one bitmask per row,
+// starting at the bottom, with the piece's leftmost column in bit 0.
+type Piece struct {
+	Width int
+	Rows  []uint64
+}
+
+// ParsePiece reads a piece drawn as ASCII art, top row first: '#' is part of
+// the piece, and '.' or ' ' is not. Pieces are at most 64 columns wide.
+func ParsePiece(art string) (Piece, error) {
+	lines := strings.Split(strings.Trim(art, "\n"), "\n")
+	p := Piece{Rows: make([]uint64, len(lines))}
+	cells := 0
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \r")
+		if len(line) > 64 {
+			return Piece{}, fmt.Errorf("row %d: %d columns is wider than 64", i+1, len(line))
+		}
+		var mask uint64
+		for x, c := range line {
+			switch c {
+			case '#':
+				mask |= 1 << x
+				cells++
+			case '.', ' ':
+			default:
+				return Piece{}, fmt.Errorf("row %d, column %d: unexpected %q", i+1, x+1, c)
+			}
+		}
+		if len(line) > p.Width {
+			p.Width = len(line)
+		}
+		p.Rows[len(lines)-1-i] = mask
+	}
+	if cells == 0 {
+		return Piece{}, errors.New("empty piece")
+	}
+	return p, nil
+}
+
+// ParsePieces reads pieces drawn as for ParsePiece, separated by blank lines.
+func ParsePieces(art string) ([]Piece, error) {
+	var ret []Piece
+	for i, block := range strings.Split(strings.ReplaceAll(art, "\r", ""), "\n\n") {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		p, err := ParsePiece(block)
+		if err != nil {
+			return nil, fmt.Errorf("piece %d: %w", i+1, err)
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+// MustParsePieces is ParsePieces, but panics on error.
+func MustParsePieces(art string) []Piece {
+	ret, err := ParsePieces(art)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// String draws p as ParsePiece reads it.
+func (p Piece) String() string {
+	sb := &strings.Builder{}
+	for i := len(p.Rows) - 1; i >= 0; i-- {
+		for x := 0; x < p.Width; x++ {
+			if p.Rows[i]&(1<<x) != 0 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Rocks are the rocks of AoC day 17, in the order they fall.
+var Rocks = MustParsePieces(`
+####
+
+.#.
+###
+.#.
+
+..#
+..#
+###
+
+#
+#
+#
+#
+
+##
+##
+`)
+
+// Jet is a push sideways: negative is left and positive is right, by that many
+// columns.
+type Jet int
+
+// ParseJets reads a sequence of jets: '<' pushes one column left and '>' one
+// column right. Whitespace is ignored.
+func ParseJets(s string) ([]Jet, error) {
+	var ret []Jet
+	for i, c := range s {
+		switch c {
+		case '<':
+			ret = append(ret, -1)
+		case '>':
+			ret = append(ret, 1)
+		case ' ', '\t', '\r', '\n':
+		default:
+			return nil, fmt.Errorf("offset %d: unexpected %q", i, c)
+		}
+	}
+	if len(ret) == 0 {
+		return nil, errors.New("no jets")
+	}
+	return ret, nil
+}
+
+// Tower drops pieces into a chamber with a floor below row 0 of its Board,
+// which is as wide as the chamber. Each piece appears SpawnX columns from the
+// left wall and SpawnGap empty rows above the highest full cell. It's then
+// pushed by the next jet, if it fits, and falls one row, if it fits, over and
+// over until it can't fall any further.
+type Tower struct {
+	Board    *Board
+	Pieces   []Piece
+	Jets     []Jet
+	SpawnX   int
+	SpawnGap int
+
+	// Dropped is the number of pieces that have come to rest. NextPiece and
+	// NextJet index the piece and jet to use next.
+	Dropped   int64
+	NextPiece int
+	NextJet   int
+
+	// Trimmed is how many rows lie below the bottom of Board: rows cut off
+	// because no piece could reach them any more, and rows skipped by
+	// Simulate.
+	Trimmed int64
+
+	// Hooks are called after each piece comes to rest, with the piece and
+	// where its bottom-left corner landed, counting Trimmed rows.
+	Hooks []func(t *Tower, p *Piece, x int, y int64)
+}
+
+// NewTower returns an empty tower width columns wide, using AoC's spawn
+// position. Every piece must fit between the spawn position and the right
+// wall.
+func NewTower(width int, pieces []Piece, jets []Jet) *Tower {
+	const spawnX = 2
+	if len(pieces) == 0 || len(jets) == 0 {
+		panic("gravity.NewTower: need pieces and jets")
+	}
+	for i, p := range pieces {
+		if p.Width > width-spawnX {
+			panic(fmt.Sprintf("gravity.NewTower: piece %d is %d wide, too wide to spawn in %d columns", i, p.Width, width))
+		}
+	}
+	return &Tower{
+		Board:    NewBoard(width),
+		Pieces:   pieces,
+		Jets:     jets,
+		SpawnX:   spawnX,
+		SpawnGap: 3,
+	}
+}
+
+// Height returns the height of the tower, including Trimmed rows.
+func (t *Tower) Height() int64 {
+	return t.Trimmed + int64(t.Board.Top())
+}
+
+// Drop drops the next piece, and returns where its bottom-left corner came to
+// rest, counting Trimmed rows.
+func (t *Tower) Drop() (x int, y int64) {
+	p := &t.Pieces[t.NextPiece]
+	t.NextPiece = (t.NextPiece + 1) % len(t.Pieces)
+
+	x, row := t.SpawnX, t.Board.Top()+t.SpawnGap
+	for {
+		jet := int(t.Jets[t.NextJet])
+		t.NextJet = (t.NextJet + 1) % len(t.Jets)
+		if t.Board.Fits(p, x+jet, row) {
+			x += jet
+		}
+		if !t.Board.Fits(p, x, row-1) {
+			break
+		}
+		row--
+	}
+	t.Board.Place(p, x, row)
+	t.Dropped++
+	y = t.Trimmed + int64(row)
+	t.trim()
+
+	for _, hook := range t.Hooks {
+		hook(t, p, x, y)
+	}
+	return x, y
+}
+
+// trim cuts off the rows no piece can reach. A falling piece moves one cell at
+// a time through empty cells, so it can only touch rows at or just below the
+// empty cells connected to the open space above the tower.
+func (t *Tower) trim() {
+	b := t.Board
+	top := b.Top()
+	seen := make([]bool, (top+1)*b.width)
+	var queue [][2]int
+	for x := 0; x < b.width; x++ {
+		seen[top*b.width+x] = true
+		queue = append(queue, [2]int{x, top})
+	}
+	lowest := top
+	for len(queue) > 0 {
+		x, y := queue[0][0], queue[0][1]
+		queue = queue[1:]
+		if y < lowest {
+			lowest = y
+		}
+		for _, n := range [3][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}} {
+			if n[0] < 0 || n[0] >= b.width || n[1] < 0 || seen[n[1]*b.width+n[0]] || b.Get(n[0], n[1]) {
+				continue
+			}
+			seen[n[1]*b.width+n[0]] = true
+			queue = append(queue, n)
+		}
+	}
+	if lowest > 1 {
+		b.Trim(lowest - 1)
+		t.Trimmed += int64(lowest - 1)
+	}
+}
+
+// Key identifies the state of a tower, apart from its height and the number
+// of pieces dropped: two towers with the same key will grow the same way from
+// then on. Keys are comparable, so they can be used as map keys to detect
+// cycles.
+type Key struct {
+	Piece, Jet int
+	Rows       string
+}
+
+// Key returns the tower's current key.
+func (t *Tower) Key() Key {
+	b := t.Board
+	words := b.rows[:b.Top()*b.stride]
+	buf := make([]byte, 8*len(words))
+	for i, w := range words {
+		binary.LittleEndian.PutUint64(buf[8*i:], w)
+	}
+	return Key{Piece: t.NextPiece, Jet: t.NextJet, Rows: string(buf)}
+}
+
+// Simulate drops pieces until n have come to rest, and returns the height of
+// the tower. Once the tower's Key repeats, Simulate skips ahead by as many
+// whole cycles as it can, adding their height to Trimmed, so n can be huge.
+func (t *Tower) Simulate(n int64) int64 {
+	type mark struct{ dropped, height int64 }
+	seen := map[Key]mark{}
+	skipped := false
+	for t.Dropped < n {
+		if !skipped {
+			k := t.Key()
+			if prev, ok := seen[k]; ok {
+				period := t.Dropped - prev.dropped
+				cycles := (n - t.Dropped) / period
+				t.Dropped += cycles * period
+				t.Trimmed += cycles * (t.Height() - prev.height)
+				skipped = true
+				continue
+			}
+			seen[k] = mark{t.Dropped, t.Height()}
+		}
+		t.Drop()
+	}
+	return t.Height()
+}
+
+// String draws the part of the tower on the Board as AoC does, top row first,
+// between walls and above a floor.
+func (t *Tower) String() string {
+	b := t.Board
+	sb := &strings.Builder{}
+	for y := b.Top() - 1; y >= 0; y-- {
+		sb.WriteByte('|')
+		b.writeRow(sb, y)
+		sb.WriteString("|\n")
+	}
+	floor := "+" + strings.Repeat("-", b.width) + "+\n"
+	if t.Trimmed > 0 {
+		floor = fmt.Sprintf("+%s+ %d more rows\n", strings.Repeat("~", b.width), t.Trimmed)
+	}
+	sb.WriteString(floor)
+	return sb.String()
+}
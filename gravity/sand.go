@@ -0,0 +1,245 @@
+package gravity
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/coord"
+)
+
+// ParseScan reads a scan of rock: one path per line, as points joined by
+// " -> ", like "498,4 -> 498,6 -> 496,6".
+func ParseScan(r io.Reader) ([][]coord.Coord, error) {
+	var ret [][]coord.Coord
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var path []coord.Coord
+		for _, field := range strings.Split(line, "->") {
+			c, err := coord.FromComma(strings.TrimSpace(field))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			path = append(path, c)
+		}
+		ret = append(ret, path)
+	}
+	return ret, scanner.Err()
+}
+
+// Outcome is what happened to a unit of sand.
+type Outcome int
+
+const (
+	// Rest means the sand came to rest.
+	Rest Outcome = iota
+	// Overflow means the sand fell past all the rock, and will fall forever.
+	Overflow
+	// Blocked means the source is covered, so no sand came out.
+	Blocked
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Rest:
+		return "rest"
+	case Overflow:
+		return "overflow"
+	case Blocked:
+		return "blocked"
+	}
+	return fmt.Sprintf("(bad outcome %d)", int(o))
+}
+
+// Sand pours sand, one unit at a time, from Source onto rock. Each unit falls
+// straight down if it can, otherwise down and to the left, otherwise down and
+// to the right, and otherwise comes to rest. Y grows downward, as in the
+// puzzle.
+//
+// Rock and Solid hold the plane from X = Left and Y = 0: Rock only the rock,
+// and Solid the rock and the sand at rest.
+type Sand struct {
+	Source coord.Coord
+	Rock   *Board
+	Solid  *Board
+	Left   int
+
+	// Bottom is the lowest row sand can reach. With a Floor, it's the row just
+	// above the floor, and sand there comes to rest; without, it's the row of
+	// the lowest rock, and sand there overflows.
+	Bottom int
+	Floor  bool
+
+	// Rested is the number of units that have come to rest.
+	Rested int
+}
+
+// NewSand returns a sand simulation for rock along the given paths, each a
+// series of horizontal and vertical lines. If floor is true, there's also an
+// endless floor two rows below the lowest rock.
+func NewSand(paths [][]coord.Coord, source coord.Coord, floor bool) (*Sand, error) {
+	if source.Y < 0 {
+		return nil, fmt.Errorf("source %v is above row 0", source)
+	}
+	minX, maxX, maxY := source.X, source.X, source.Y
+	for _, path := range paths {
+		if len(path) == 0 {
+			return nil, errors.New("empty path")
+		}
+		for i, c := range path {
+			if c.Y < 0 {
+				return nil, fmt.Errorf("rock at %v is above row 0", c)
+			}
+			if i > 0 && c.X != path[i-1].X && c.Y != path[i-1].Y {
+				return nil, fmt.Errorf("diagonal line from %v to %v", path[i-1], c)
+			}
+			minX, maxX, maxY = aoc.Min(minX, c.X), aoc.Max(maxX, c.X), aoc.Max(maxY, c.Y)
+		}
+	}
+
+	s := &Sand{Source: source, Bottom: maxY, Floor: floor}
+	if floor {
+		s.Bottom = maxY + 1
+	}
+	// sand spreads at most one column per row it falls, so it stays within
+	// this triangle below the source, plus one column for overflow
+	depth := s.Bottom - source.Y
+	minX = aoc.Min(minX, source.X-depth) - 1
+	maxX = aoc.Max(maxX, source.X+depth) + 1
+	s.Left = minX
+	s.Rock = NewBoard(maxX - minX + 1)
+
+	for _, path := range paths {
+		prev := path[0]
+		s.Rock.Set(prev.X-s.Left, prev.Y)
+		for _, c := range path[1:] {
+			for prev != c {
+				prev.X += sign(c.X - prev.X)
+				prev.Y += sign(c.Y - prev.Y)
+				s.Rock.Set(prev.X-s.Left, prev.Y)
+			}
+		}
+	}
+	s.Solid = s.Rock.Copy()
+	return s, nil
+}
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	}
+	return 0
+}
+
+// At reports whether there's rock or sand at c.
+func (s *Sand) At(c coord.Coord) bool {
+	return s.Solid.Get(c.X-s.Left, c.Y)
+}
+
+// Drop pours one unit of sand, and returns what happened to it and where it
+// ended up.
+func (s *Sand) Drop() (Outcome, coord.Coord) {
+	pos := s.Source
+	if s.At(pos) {
+		return Blocked, pos
+	}
+falling:
+	for {
+		if pos.Y >= s.Bottom {
+			if !s.Floor {
+				return Overflow, pos
+			}
+			break
+		}
+		for _, next := range [3]coord.Coord{pos.South(), pos.SouthWest(), pos.SouthEast()} {
+			if !s.At(next) {
+				pos = next
+				continue falling
+			}
+		}
+		break
+	}
+	s.Solid.Set(pos.X-s.Left, pos.Y)
+	s.Rested++
+	return Rest, pos
+}
+
+// Fill pours sand until a unit doesn't come to rest, and returns how it ended
+// and the total number of units at rest. Each hook is called after every
+// unit that comes to rest.
+func (s *Sand) Fill(hooks ...func(s *Sand, at coord.Coord)) (Outcome, int) {
+	for {
+		o, at := s.Drop()
+		if o != Rest {
+			return o, s.Rested
+		}
+		for _, hook := range hooks {
+			hook(s, at)
+		}
+	}
+}
+
+// String draws the simulation as AoC does: '#' for rock, 'o' for sand, '+'
+// for the source and '.' for air.
+func (s *Sand) String() string {
+	sb := &strings.Builder{}
+	for y := 0; y <= s.Bottom; y++ {
+		for x := 0; x < s.Rock.Width(); x++ {
+			switch {
+			case x+s.Left == s.Source.X && y == s.Source.Y && !s.Solid.Get(x, y):
+				sb.WriteByte('+')
+			case s.Rock.Get(x, y):
+				sb.WriteByte('#')
+			case s.Solid.Get(x, y):
+				sb.WriteByte('o')
+			default:
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Image draws the simulation scale pixels per cell, with a count of the sand
+// at rest in the corner.
+func (s *Sand) Image(scale int) *image.Paletted {
+	height := s.Bottom + 1
+	if s.Floor {
+		height++
+	}
+	img := image.NewPaletted(image.Rect(0, 0, s.Rock.Width()*scale, height*scale), aoc.TolVibrant)
+	fill := func(x, y int, c color.Color) {
+		idx := uint8(img.Palette.Index(c))
+		for py := y * scale; py < (y+1)*scale; py++ {
+			for px := x * scale; px < (x+1)*scale; px++ {
+				img.SetColorIndex(px, py, idx)
+			}
+		}
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < s.Rock.Width(); x++ {
+			switch {
+			case s.Floor && y == height-1, s.Rock.Get(x, y):
+				fill(x, y, aoc.TolVibrantGrey)
+			case s.Solid.Get(x, y):
+				fill(x, y, aoc.TolVibrantOrange)
+			}
+		}
+	}
+	fill(s.Source.X-s.Left, s.Source.Y, aoc.TolVibrantRed)
+	aoc.Typeset(img, image.Point{}, fmt.Sprint(s.Rested), aoc.TolVibrantTeal, aoc.TypesetOpts{Scale: scale})
+	return img
+}
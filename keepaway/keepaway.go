@@ -0,0 +1,410 @@
+// Package keepaway simulates the monkeys of AoC 2022 day 11, who play keep
+// away with your items: each monkey in turn inspects the items it holds, which
+// changes how worried you are about them, and throws each to another monkey
+// depending on the result.
+package keepaway
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/asymmetricia/aoc22/aoc/mathx"
+)
+
+type OpKind uint8
+
+const (
+	// Add is old + N.
+	Add OpKind = iota
+	// Mul is old * N.
+	Mul
+	// Double is old + old.
+	Double
+	// Square is old * old.
+	Square
+)
+
+// Op is a compiled operation, which gives a new worry level from the old one.
+type Op struct {
+	Kind OpKind
+	N    int64
+}
+
+// ParseOp reads an operation like "new = old * 19" or "new = old + old".
+func ParseOp(s string) (Op, error) {
+	f := strings.Fields(s)
+	if len(f) != 5 || f[0] != "new" || f[1] != "=" || f[2] != "old" {
+		return Op{}, fmt.Errorf("expected new = old <op> <operand>, got %q", s)
+	}
+	var op Op
+	switch {
+	case f[3] == "+" && f[4] == "old":
+		op.Kind = Double
+	case f[3] == "*" && f[4] == "old":
+		op.Kind = Square
+	case f[3] == "+" || f[3] == "*":
+		n, err := strconv.ParseInt(f[4], 10, 64)
+		if err != nil {
+			return Op{}, fmt.Errorf("bad operand %q in %q", f[4], s)
+		}
+		op.Kind, op.N = Add, n
+		if f[3] == "*" {
+			op.Kind = Mul
+		}
+	default:
+		return Op{}, fmt.Errorf("bad operator %q in %q", f[3], s)
+	}
+	return op, nil
+}
+
+func (o Op) String() string {
+	switch o.Kind {
+	case Add:
+		return fmt.Sprintf("new = old + %d", o.N)
+	case Mul:
+		return fmt.Sprintf("new = old * %d", o.N)
+	case Double:
+		return "new = old + old"
+	case Square:
+		return "new = old * old"
+	}
+	return fmt.Sprintf("(bad op kind %d)", int(o.Kind))
+}
+
+// Apply returns the new worry level, or an error wrapping mathx.ErrOverflow if
+// it doesn't fit in an int64.
+func (o Op) Apply(old int64) (int64, error) {
+	var ret int64
+	var ok bool
+	switch o.Kind {
+	case Add:
+		ret, ok = mathx.AddChecked(old, o.N)
+	case Mul:
+		ret, ok = mathx.MulChecked(old, o.N)
+	case Double:
+		ret, ok = mathx.AddChecked(old, old)
+	case Square:
+		ret, ok = mathx.MulChecked(old, old)
+	}
+	if !ok {
+		return 0, fmt.Errorf("%v with old = %d: %w", o, old, mathx.ErrOverflow)
+	}
+	return ret, nil
+}
+
+// ApplyMod returns the new worry level modulo m. It never overflows.
+func (o Op) ApplyMod(old, m int64) int64 {
+	switch o.Kind {
+	case Add:
+		return addMod(mathx.Mod(old, m), mathx.Mod(o.N, m), m)
+	case Mul:
+		return mathx.MulMod(old, o.N, m)
+	case Double:
+		old = mathx.Mod(old, m)
+		return addMod(old, old, m)
+	case Square:
+		return mathx.MulMod(old, old, m)
+	}
+	panic(fmt.Sprintf("keepaway: bad op kind %d", int(o.Kind)))
+}
+
+// addMod returns a+b modulo m, for a and b in [0, m), without overflowing.
+func addMod(a, b, m int64) int64 {
+	if a >= m-b {
+		return a - (m - b)
+	}
+	return a + b
+}
+
+// Monkey holds items and knows how to inspect and throw them.
+type Monkey struct {
+	ID    int
+	Items []int64
+	Op    Op
+
+	// Divisor decides where items go: to IfTrue if the worry level is
+	// divisible by it, and to IfFalse otherwise.
+	Divisor int64
+	IfTrue  int
+	IfFalse int
+
+	// Inspections is the number of items the monkey has inspected.
+	Inspections int
+}
+
+// Relief is what happens to your worry about an item when a monkey inspects
+// it.
+type Relief interface {
+	// Inspect returns the new worry level about an item, after op is applied
+	// to the old one.
+	Inspect(op Op, worry int64) (int64, error)
+}
+
+// DivideBy is relief that the monkey didn't damage the item: after the
+// operation, the worry level is divided by it, rounding down. AoC's part 1 uses
+// DivideBy(3). It must be positive.
+type DivideBy int64
+
+func (d DivideBy) Inspect(op Op, worry int64) (int64, error) {
+	if d < 1 {
+		return 0, fmt.Errorf("relief divisor %d is not positive", int64(d))
+	}
+	w, err := op.Apply(worry)
+	if err != nil {
+		return 0, err
+	}
+	return w / int64(d), nil
+}
+
+// Modulo keeps worry levels manageable without any relief: only the residue of
+// a worry level modulo every monkey's Divisor matters, so worry can be kept
+// modulo a common multiple of them. See ModuloLCM. It must be positive.
+type Modulo int64
+
+func (m Modulo) Inspect(op Op, worry int64) (int64, error) {
+	if m < 1 {
+		return 0, fmt.Errorf("relief modulus %d is not positive", int64(m))
+	}
+	return op.ApplyMod(worry, int64(m)), nil
+}
+
+// ModuloLCM returns the smallest Modulo for the monkeys: the least common
+// multiple of their Divisors.
+func ModuloLCM(monkeys []*Monkey) (Modulo, error) {
+	divisors := make([]int64, len(monkeys))
+	for i, m := range monkeys {
+		divisors[i] = m.Divisor
+	}
+	lcm, err := mathx.LCM(divisors...)
+	return Modulo(lcm), err
+}
+
+// Parse reads monkey specs separated by blank lines, like:
+//
+//	Monkey 0:
+//	  Starting items: 79, 98
+//	  Operation: new = old * 19
+//	  Test: divisible by 23
+//	    If true: throw to monkey 2
+//	    If false: throw to monkey 3
+//
+// Monkeys must be numbered from 0, in order, and only throw to each other.
+func Parse(r io.Reader) ([]*Monkey, error) {
+	var ret []*Monkey
+	var m *Monkey
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value, got %q", lineNo, line)
+		}
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(key, "Monkey ") {
+			id, err := strconv.Atoi(strings.TrimPrefix(key, "Monkey "))
+			if err != nil || id != len(ret) || value != "" {
+				return nil, fmt.Errorf("line %d: expected Monkey %d:, got %q", lineNo, len(ret), line)
+			}
+			m = &Monkey{ID: id, IfTrue: -1, IfFalse: -1}
+			ret = append(ret, m)
+			continue
+		}
+		if m == nil {
+			return nil, fmt.Errorf("line %d: %q before any monkey", lineNo, line)
+		}
+
+		var err error
+		switch key {
+		case "Starting items":
+			m.Items, err = parseItems(value)
+		case "Operation":
+			m.Op, err = ParseOp(value)
+		case "Test":
+			var s string
+			s, ok = cutPrefix(value, "divisible by ")
+			m.Divisor, err = strconv.ParseInt(s, 10, 64)
+			if !ok || err != nil || m.Divisor <= 0 {
+				err = fmt.Errorf("expected divisible by <positive number>, got %q", value)
+			}
+		case "If true":
+			m.IfTrue, err = parseThrow(value)
+		case "If false":
+			m.IfFalse, err = parseThrow(value)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: monkey %d: %w", lineNo, m.ID, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ret) == 0 {
+		return nil, errors.New("no monkeys")
+	}
+
+	for _, m := range ret {
+		switch {
+		case m.Divisor == 0:
+			return nil, fmt.Errorf("monkey %d: no test", m.ID)
+		case m.IfTrue < 0 || m.IfFalse < 0:
+			return nil, fmt.Errorf("monkey %d: missing throw target", m.ID)
+		case m.IfTrue >= len(ret):
+			return nil, fmt.Errorf("monkey %d: no monkey %d to throw to", m.ID, m.IfTrue)
+		case m.IfFalse >= len(ret):
+			return nil, fmt.Errorf("monkey %d: no monkey %d to throw to", m.ID, m.IfFalse)
+		}
+	}
+	return ret, nil
+}
+
+// ParseString is Parse for a string.
+func ParseString(s string) ([]*Monkey, error) {
+	return Parse(strings.NewReader(s))
+}
+
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+func parseItems(s string) ([]int64, error) {
+	var ret []int64
+	if s == "" {
+		return ret, nil
+	}
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.ParseInt(strings.TrimSpace(f), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad item %q", f)
+		}
+		ret = append(ret, n)
+	}
+	return ret, nil
+}
+
+func parseThrow(s string) (int, error) {
+	id, ok := cutPrefix(s, "throw to monkey ")
+	n, err := strconv.Atoi(id)
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("expected throw to monkey <number>, got %q", s)
+	}
+	return n, nil
+}
+
+// Troop is a group of monkeys playing keep away.
+type Troop struct {
+	Monkeys []*Monkey
+	Relief  Relief
+
+	// Rounds is the number of rounds played.
+	Rounds int
+	// History holds each monkey's total Inspections after each round.
+	History [][]int
+
+	// Hooks are called after every round.
+	Hooks []func(t *Troop)
+}
+
+// NewTroop returns a troop of the monkeys, which will use the given relief.
+func NewTroop(monkeys []*Monkey, relief Relief) *Troop {
+	return &Troop{Monkeys: monkeys, Relief: relief}
+}
+
+// Round plays one round: each monkey in turn inspects and throws every item it
+// holds at the start of its turn, in order; an item a monkey throws to itself
+// waits for its next turn. If a worry level overflows, Round stops where it is
+// and returns an error.
+func (t *Troop) Round() error {
+	for _, m := range t.Monkeys {
+		items := m.Items
+		m.Items = nil
+		for i, item := range items {
+			worry, err := t.Relief.Inspect(m.Op, item)
+			if err != nil {
+				// keep the items not yet thrown, ahead of any thrown to itself
+				m.Items = append(items[i:len(items):len(items)], m.Items...)
+				return fmt.Errorf("round %d: monkey %d: %w", t.Rounds+1, m.ID, err)
+			}
+			m.Inspections++
+			to := m.IfFalse
+			if worry%m.Divisor == 0 {
+				to = m.IfTrue
+			}
+			t.Monkeys[to].Items = append(t.Monkeys[to].Items, worry)
+		}
+	}
+
+	t.Rounds++
+	counts := make([]int, len(t.Monkeys))
+	for i, m := range t.Monkeys {
+		counts[i] = m.Inspections
+	}
+	t.History = append(t.History, counts)
+	for _, hook := range t.Hooks {
+		hook(t)
+	}
+	return nil
+}
+
+// Run plays n rounds.
+func (t *Troop) Run(n int) error {
+	for i := 0; i < n; i++ {
+		if err := t.Round(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MonkeyBusiness returns the product of the two largest Inspections counts.
+func (t *Troop) MonkeyBusiness() int {
+	counts := make([]int, len(t.Monkeys))
+	for i, m := range t.Monkeys {
+		counts[i] = m.Inspections
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	if len(counts) < 2 {
+		return 0
+	}
+	return counts[0] * counts[1]
+}
+
+// MonkeyState is the state of one monkey, for State.
+type MonkeyState struct {
+	ID          int     `json:"id"`
+	Items       []int64 `json:"items"`
+	Inspections int     `json:"inspections"`
+}
+
+// State is a snapshot of a troop between rounds, meant to be marshaled to JSON
+// for visualization.
+type State struct {
+	Round   int           `json:"round"`
+	Monkeys []MonkeyState `json:"monkeys"`
+}
+
+// State returns a snapshot of t, sharing nothing with it.
+func (t *Troop) State() State {
+	s := State{Round: t.Rounds, Monkeys: make([]MonkeyState, len(t.Monkeys))}
+	for i, m := range t.Monkeys {
+		s.Monkeys[i] = MonkeyState{
+			ID:          m.ID,
+			Items:       append([]int64{}, m.Items...),
+			Inspections: m.Inspections,
+		}
+	}
+	return s
+}
@@ -0,0 +1,196 @@
+package keepaway
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/asymmetricia/aoc22/aoc/mathx"
+)
+
+const example = `Monkey 0:
+  Starting items: 79, 98
+  Operation: new = old * 19
+  Test: divisible by 23
+    If true: throw to monkey 2
+    If false: throw to monkey 3
+
+Monkey 1:
+  Starting items: 54, 65, 75, 74
+  Operation: new = old + 6
+  Test: divisible by 19
+    If true: throw to monkey 2
+    If false: throw to monkey 0
+
+Monkey 2:
+  Starting items: 79, 60, 97
+  Operation: new = old * old
+  Test: divisible by 13
+    If true: throw to monkey 1
+    If false: throw to monkey 3
+
+Monkey 3:
+  Starting items: 74
+  Operation: new = old + 3
+  Test: divisible by 17
+    If true: throw to monkey 0
+    If false: throw to monkey 1
+`
+
+func TestParse(t *testing.T) {
+	monkeys, err := ParseString(example)
+	require.NoError(t, err)
+	require.Len(t, monkeys, 4)
+	require.Equal(t, &Monkey{
+		ID:      2,
+		Items:   []int64{79, 60, 97},
+		Op:      Op{Kind: Square},
+		Divisor: 13,
+		IfTrue:  1,
+		IfFalse: 3,
+	}, monkeys[2])
+
+	bad := []string{
+		"",
+		"Monkey 1:\n",
+		"Monkey 0:\n  Test: divisible by 0\n",
+		"Monkey 0:\n  Operation: new = old - 3\n",
+		"Monkey 0:\n  Test: divisible by 2\n  If true: throw to monkey 0\n",
+		"Monkey 0:\n  Test: divisible by 2\n  If true: throw to monkey 0\n  If false: throw to monkey 1\n",
+		"Monkey 0:\n  Favorite color: blue\n",
+	}
+	for _, s := range bad {
+		_, err := ParseString(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestOp(t *testing.T) {
+	tests := []struct {
+		spec string
+		old  int64
+		want int64
+	}{
+		{"new = old * 19", 79, 1501},
+		{"new = old + 6", 54, 60},
+		{"new = old * old", 79, 6241},
+		{"new = old + old", 21, 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			op, err := ParseOp(tt.spec)
+			require.NoError(t, err)
+			require.Equal(t, tt.spec, op.String())
+			got, err := op.Apply(tt.old)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.want%97, op.ApplyMod(tt.old, 97))
+		})
+	}
+
+	_, err := Op{Kind: Square}.Apply(math.MaxInt32 * 2)
+	require.True(t, errors.Is(err, mathx.ErrOverflow))
+
+	// near the top of int64, ApplyMod still works
+	const m = math.MaxInt64 - 24
+	require.EqualValues(t, m-2, Op{Kind: Double}.ApplyMod(m-1, m))
+	require.EqualValues(t, 1, Op{Kind: Add, N: 2}.ApplyMod(m-1, m))
+}
+
+func TestTroop(t *testing.T) {
+	tests := []struct {
+		name   string
+		relief func([]*Monkey) Relief
+		rounds int
+		counts []int
+		want   int
+	}{
+		{
+			name:   "part 1",
+			relief: func([]*Monkey) Relief { return DivideBy(3) },
+			rounds: 20,
+			counts: []int{101, 95, 7, 105},
+			want:   10605,
+		},
+		{
+			name: "part 2",
+			relief: func(ms []*Monkey) Relief {
+				m, err := ModuloLCM(ms)
+				require.NoError(t, err)
+				return m
+			},
+			rounds: 10000,
+			counts: []int{52166, 47830, 1938, 52013},
+			want:   2713310158,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monkeys, err := ParseString(example)
+			require.NoError(t, err)
+			troop := NewTroop(monkeys, tt.relief(monkeys))
+			require.NoError(t, troop.Run(tt.rounds))
+			require.Equal(t, tt.want, troop.MonkeyBusiness())
+			require.Len(t, troop.History, tt.rounds)
+			require.Equal(t, tt.counts, troop.History[tt.rounds-1])
+		})
+	}
+}
+
+func TestTroop_State(t *testing.T) {
+	monkeys, err := ParseString(example)
+	require.NoError(t, err)
+	troop := NewTroop(monkeys, DivideBy(3))
+	require.NoError(t, troop.Run(1))
+	require.Equal(t, []int{2, 4, 3, 5}, troop.History[0])
+
+	buf, err := json.Marshal(troop.State())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"round": 1, "monkeys": [
+		{"id": 0, "items": [20, 23, 27, 26], "inspections": 2},
+		{"id": 1, "items": [2080, 25, 167, 207, 401, 1046], "inspections": 4},
+		{"id": 2, "items": [], "inspections": 3},
+		{"id": 3, "items": [], "inspections": 5}
+	]}`, string(buf))
+
+	// without relief or a modulus, worry overflows
+	troop = NewTroop(monkeys, DivideBy(1))
+	err = troop.Run(1000)
+	require.True(t, errors.Is(err, mathx.ErrOverflow))
+
+	for _, relief := range []Relief{DivideBy(0), Modulo(0), Modulo(-3)} {
+		monkeys, err := ParseString(example)
+		require.NoError(t, err)
+		troop := NewTroop(monkeys, relief)
+		require.Error(t, troop.Run(1), "%#v", relief)
+		require.Equal(t, []int64{79, 98}, troop.Monkeys[0].Items, "%#v", relief)
+	}
+}
+
+// TestTroop_SelfThrow checks that an item a monkey throws to itself isn't lost,
+// and waits for the monkey's next turn.
+func TestTroop_SelfThrow(t *testing.T) {
+	monkeys, err := ParseString(`Monkey 0:
+  Starting items: 1, 2
+  Operation: new = old + 1
+  Test: divisible by 2
+    If true: throw to monkey 0
+    If false: throw to monkey 1
+
+Monkey 1:
+  Starting items:
+  Operation: new = old * 1
+  Test: divisible by 7
+    If true: throw to monkey 0
+    If false: throw to monkey 0
+`)
+	require.NoError(t, err)
+	troop := NewTroop(monkeys, Modulo(100))
+	require.NoError(t, troop.Round())
+	// 1 became 2 and stayed; 2 became 3 and went to monkey 1, then back
+	require.Equal(t, []int64{2, 3}, troop.Monkeys[0].Items)
+	require.Equal(t, []int{2, 1}, troop.History[0])
+}
@@ -0,0 +1,390 @@
+// Package nested handles nested lists of integers, like the distress signal
+// packets of AoC 2022 day 13: "[1,[2,[3,4]],5]".
+package nested
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+type Kind uint8
+
+const (
+	Int Kind = iota
+	List
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Int:
+		return "int"
+	case List:
+		return "list"
+	}
+	return fmt.Sprintf("(bad kind %d)", int(k))
+}
+
+// Value is either an integer or a list of values, according to its Kind. The
+// zero value is the integer 0.
+type Value struct {
+	Kind  Kind
+	N     int
+	Items []Value
+}
+
+// OfInt returns the integer value n.
+func OfInt(n int) Value {
+	return Value{Kind: Int, N: n}
+}
+
+// OfList returns the list of the given values.
+func OfList(items ...Value) Value {
+	if items == nil {
+		items = []Value{}
+	}
+	return Value{Kind: List, Items: items}
+}
+
+// Compare returns -1 if v comes before w, 1 if it comes after, and 0 if
+// they're equal. Integers compare numerically; lists compare item by item, and
+// a list that runs out first comes first. When an integer is compared to a
+// list, it's treated as a list holding just that integer.
+func (v Value) Compare(w Value) int {
+	switch {
+	case v.Kind == Int && w.Kind == Int:
+		switch {
+		case v.N < w.N:
+			return -1
+		case v.N > w.N:
+			return 1
+		}
+		return 0
+	case v.Kind == Int:
+		one := [1]Value{v}
+		return compareLists(one[:], w.Items)
+	case w.Kind == Int:
+		one := [1]Value{w}
+		return compareLists(v.Items, one[:])
+	}
+	return compareLists(v.Items, w.Items)
+}
+
+func compareLists(a, b []Value) int {
+	for i := range a {
+		if i >= len(b) {
+			return 1
+		}
+		if c := a[i].Compare(b[i]); c != 0 {
+			return c
+		}
+	}
+	if len(a) < len(b) {
+		return -1
+	}
+	return 0
+}
+
+// Less reports whether v comes before w.
+func (v Value) Less(w Value) bool {
+	return v.Compare(w) < 0
+}
+
+// Equal reports whether v and w are the same. Unlike Compare, it doesn't
+// consider 1 and [1] equal.
+func (v Value) Equal(w Value) bool {
+	if v.Kind != w.Kind {
+		return false
+	}
+	if v.Kind == Int {
+		return v.N == w.N
+	}
+	if len(v.Items) != len(w.Items) {
+		return false
+	}
+	for i := range v.Items {
+		if !v.Items[i].Equal(w.Items[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns v in canonical form: no whitespace, and integers in decimal
+// without leading zeroes.
+func (v Value) String() string {
+	return string(v.Append(nil))
+}
+
+// Append appends the canonical form of v to buf and returns the result.
+func (v Value) Append(buf []byte) []byte {
+	if v.Kind == Int {
+		return strconv.AppendInt(buf, int64(v.N), 10)
+	}
+	buf = append(buf, '[')
+	for i, item := range v.Items {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = item.Append(buf)
+	}
+	return append(buf, ']')
+}
+
+func (v Value) MarshalText() ([]byte, error) {
+	return v.Append(nil), nil
+}
+
+func (v *Value) UnmarshalText(text []byte) error {
+	p, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler; the canonical form is valid JSON.
+func (v Value) MarshalJSON() ([]byte, error) {
+	return v.Append(nil), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler using Parse, so it accepts only
+// integers and lists.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	return v.UnmarshalText(data)
+}
+
+// Values is a slice of values that implements sort.Interface, ordered by
+// Compare.
+type Values []Value
+
+func (vs Values) Len() int           { return len(vs) }
+func (vs Values) Less(i, j int) bool { return vs[i].Compare(vs[j]) < 0 }
+func (vs Values) Swap(i, j int)      { vs[i], vs[j] = vs[j], vs[i] }
+
+// SyntaxError describes where and why Parse failed.
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Msg)
+}
+
+// Parse reads a value: a decimal integer, optionally negative, or a list of
+// values in square brackets separated by commas. Spaces, tabs and newlines are
+// allowed between tokens.
+//
+// Parse validates s before building anything, then makes one allocation to
+// hold every item of every list, however deeply the value is nested. Use
+// ParseInto to parse many values without allocating at all.
+func Parse(s string) (Value, error) {
+	v, _, err := ParseInto(s, nil)
+	return v, err
+}
+
+// ParseInto is Parse, but stores the list items of the value in buf's spare
+// capacity, appending them to buf, and returns the extended buf. It allocates
+// only if buf's capacity is too small: parsing needs room for twice the items
+// beyond len(buf), half of it scratch space. Values parsed earlier into buf
+// stay valid, as long as the caller doesn't overwrite buf[:len(buf)], so a
+// caller can reuse one buffer for a whole input:
+//
+//	buf := make([]nested.Value, 0, 4096)
+//	for _, line := range lines {
+//		v, buf, err = nested.ParseInto(line, buf)
+//		...
+//	}
+func ParseInto(s string, buf []Value) (Value, []Value, error) {
+	count, err := scan(s)
+	if err != nil {
+		return Value{}, buf, err
+	}
+	base := len(buf)
+	if need := base + 2*count; cap(buf) < need {
+		if need < 2*cap(buf) {
+			need = 2 * cap(buf)
+		}
+		grown := make([]Value, base, need)
+		copy(grown, buf)
+		buf = grown
+	}
+	p := parser{
+		s:     s,
+		out:   buf[base:base:base+count],
+		stack: buf[base+count : base+count : base+2*count],
+	}
+	p.skipSpace()
+	v := p.value()
+	return v, buf[:base+count], nil
+}
+
+// MustParse is Parse, but panics on error.
+func MustParse(s string) Value {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// scan checks that s holds exactly one well-formed value, and returns how many
+// list items it contains at every level.
+func scan(s string) (items int, err error) {
+	fail := func(i int, format string, args ...any) (int, error) {
+		return 0, &SyntaxError{Offset: i, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	depth := 0
+	// expectValue is true after '[' or ',' and at the start; afterValue is
+	// true after an integer or ']'.
+	expectValue, afterValue, done := true, false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isSpace(c):
+			continue
+		case done:
+			return fail(i, "unexpected %q after value", c)
+		case c == '[':
+			if !expectValue {
+				return fail(i, "unexpected '['")
+			}
+			if depth > 0 {
+				items++
+			}
+			depth++
+			expectValue, afterValue = true, false
+		case c == ']':
+			if depth == 0 {
+				return fail(i, "unexpected ']'")
+			}
+			if !afterValue && !isEmptyList(s, i) {
+				return fail(i, "expected value before ']'")
+			}
+			depth--
+			expectValue, afterValue = false, true
+			done = depth == 0
+		case c == ',':
+			if depth == 0 || !afterValue {
+				return fail(i, "unexpected ','")
+			}
+			expectValue, afterValue = true, false
+		case c == '-' || c >= '0' && c <= '9':
+			if !expectValue {
+				return fail(i, "unexpected %q", c)
+			}
+			j := i
+			if c == '-' {
+				j++
+			}
+			start := j
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			if j == start {
+				return fail(i, "expected digits after '-'")
+			}
+			if _, ok := atoi(s[i:j]); !ok {
+				return fail(i, "integer %s out of range", s[i:j])
+			}
+			if depth > 0 {
+				items++
+			}
+			i = j - 1
+			expectValue, afterValue = false, true
+			done = depth == 0
+		default:
+			return fail(i, "unexpected %q", c)
+		}
+	}
+	if !done {
+		return fail(len(s), "unexpected end of input")
+	}
+	return items, nil
+}
+
+// isEmptyList reports whether the ']' at s[i] closes a list with no items, i.e.
+// the previous non-space byte is '['.
+func isEmptyList(s string, i int) bool {
+	for i--; i >= 0 && isSpace(s[i]); i-- {
+	}
+	return i >= 0 && s[i] == '['
+}
+
+func atoi(s string) (int, bool) {
+	neg := s[0] == '-'
+	if neg {
+		s = s[1:]
+	}
+	// accumulate negatively, so the most negative int fits
+	n := 0
+	for i := 0; i < len(s); i++ {
+		d := int(s[i] - '0')
+		if n < (math.MinInt+d)/10 {
+			return 0, false
+		}
+		n = n*10 - d
+	}
+	if !neg {
+		if n == math.MinInt {
+			return 0, false
+		}
+		n = -n
+	}
+	return n, true
+}
+
+// parser builds the value in s, which scan has already validated. The items of
+// each list collect on stack until the list is closed, and are then moved to
+// out, so each list's items end up next to each other.
+type parser struct {
+	s     string
+	i     int
+	out   []Value
+	stack []Value
+}
+
+func (p *parser) skipSpace() {
+	for p.i < len(p.s) && isSpace(p.s[p.i]) {
+		p.i++
+	}
+}
+
+func (p *parser) value() Value {
+	if p.s[p.i] != '[' {
+		j := p.i + 1
+		for j < len(p.s) && p.s[j] >= '0' && p.s[j] <= '9' {
+			j++
+		}
+		n, _ := atoi(p.s[p.i:j])
+		p.i = j
+		p.skipSpace()
+		return OfInt(n)
+	}
+
+	p.i++
+	p.skipSpace()
+	mark := len(p.stack)
+	for p.s[p.i] != ']' {
+		p.stack = append(p.stack, p.value())
+		if p.s[p.i] == ',' {
+			p.i++
+			p.skipSpace()
+		}
+	}
+	// skip the ']'
+	p.i++
+	p.skipSpace()
+
+	start := len(p.out)
+	p.out = append(p.out, p.stack[mark:]...)
+	p.stack = p.stack[:mark]
+	return Value{Kind: List, Items: p.out[start:len(p.out):len(p.out)]}
+}
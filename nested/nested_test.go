@@ -0,0 +1,215 @@
+package nested
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue_Compare(t *testing.T) {
+	// from the day 13 example
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"[1,1,3,1,1]", "[1,1,5,1,1]", -1},
+		{"[[1],[2,3,4]]", "[[1],4]", -1},
+		{"[9]", "[[8,7,6]]", 1},
+		{"[[4,4],4,4]", "[[4,4],4,4,4]", -1},
+		{"[7,7,7,7]", "[7,7,7]", 1},
+		{"[]", "[3]", -1},
+		{"[[[]]]", "[[]]", 1},
+		{"[1,[2,[3,[4,[5,6,7]]]],8,9]", "[1,[2,[3,[4,[5,6,0]]]],8,9]", 1},
+		{"[2,3,4]", "[4]", -1},
+		{"1", "[1]", 0},
+		{"[]", "[]", 0},
+	}
+	for _, tt := range tests {
+		a, b := MustParse(tt.a), MustParse(tt.b)
+		require.Equal(t, tt.want, a.Compare(b), "%s vs %s", tt.a, tt.b)
+		require.Equal(t, -tt.want, b.Compare(a), "%s vs %s", tt.b, tt.a)
+	}
+}
+
+func TestValues_Sort(t *testing.T) {
+	input := `[1,1,3,1,1]
+[1,1,5,1,1]
+[[1],[2,3,4]]
+[[1],4]
+[9]
+[[8,7,6]]
+[[4,4],4,4]
+[[4,4],4,4,4]
+[7,7,7,7]
+[7,7,7]
+[]
+[3]
+[[[]]]
+[[]]
+[1,[2,[3,[4,[5,6,7]]]],8,9]
+[1,[2,[3,[4,[5,6,0]]]],8,9]
+[[2]]
+[[6]]`
+	var packets Values
+	for _, line := range strings.Split(input, "\n") {
+		packets = append(packets, MustParse(line))
+	}
+	sort.Sort(packets)
+
+	var got []string
+	for _, p := range packets {
+		got = append(got, p.String())
+	}
+	require.Equal(t, []string{
+		"[]",
+		"[[]]",
+		"[[[]]]",
+		"[1,1,3,1,1]",
+		"[1,1,5,1,1]",
+		"[[1],[2,3,4]]",
+		"[1,[2,[3,[4,[5,6,0]]]],8,9]",
+		"[1,[2,[3,[4,[5,6,7]]]],8,9]",
+		"[[1],4]",
+		"[[2]]",
+		"[3]",
+		"[[4,4],4,4]",
+		"[[4,4],4,4,4]",
+		"[[6]]",
+		"[7,7,7]",
+		"[7,7,7,7]",
+		"[[8,7,6]]",
+		"[9]",
+	}, got)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Value
+		err  int
+	}{
+		{"[]", OfList(), -1},
+		{" [ 1 , [ ] ,-2 ] \n", OfList(OfInt(1), OfList(), OfInt(-2)), -1},
+		{"007", OfInt(7), -1},
+		{strconv.Itoa(math.MinInt), OfInt(math.MinInt), -1},
+		{"", Value{}, 0},
+		{"[", Value{}, 1},
+		{"[1,]", Value{}, 3},
+		{"[,1]", Value{}, 1},
+		{"[1 2]", Value{}, 3},
+		{"[1]]", Value{}, 3},
+		{"[1][2]", Value{}, 3},
+		{"[-]", Value{}, 1},
+		{"[x]", Value{}, 1},
+		{"99999999999999999999", Value{}, 0},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if tt.err >= 0 {
+			var syntax *SyntaxError
+			require.ErrorAs(t, err, &syntax, "%q", tt.in)
+			require.Equal(t, tt.err, syntax.Offset, "%q: %v", tt.in, err)
+			continue
+		}
+		require.NoError(t, err, "%q", tt.in)
+		require.True(t, tt.want.Equal(got), "%q: got %v", tt.in, got)
+	}
+}
+
+const allocsInput = "[1,[2,[3,[4,[5,6,7]]]],8,9,[[],[[]],[10,11]]]"
+
+func TestParse_Allocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = Parse(allocsInput)
+	})
+	require.Equal(t, 1.0, allocs)
+
+	buf := make([]Value, 0, 64)
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _, _ = ParseInto(allocsInput, buf)
+	})
+	require.Equal(t, 0.0, allocs)
+}
+
+func TestParseInto(t *testing.T) {
+	// too small, so it has to grow partway through
+	buf := make([]Value, 0, 3)
+	var vs []Value
+	for _, in := range []string{"[1,[2,3]]", "4", "[[],[5]]", "[6,[7,[8]]]"} {
+		v, next, err := ParseInto(in, buf)
+		require.NoError(t, err)
+		require.Equal(t, in, v.String())
+		require.GreaterOrEqual(t, len(next), len(buf))
+		buf = next
+		vs = append(vs, v)
+	}
+	// values parsed earlier survive later parses and growth
+	require.Equal(t, "[1,[2,3]] 4 [[],[5]] [6,[7,[8]]]", fmt.Sprint(vs[0], " ", vs[1], " ", vs[2], " ", vs[3]))
+
+	_, next, err := ParseInto("[1,", buf)
+	require.Error(t, err)
+	require.Equal(t, len(buf), len(next))
+}
+
+func BenchmarkParse(b *testing.B) {
+	// Parse makes one allocation per call, for the arena of list items
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = Parse(allocsInput)
+		}
+	})
+	// ParseInto with a big enough buffer makes none
+	b.Run("ParseInto", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]Value, 0, 64)
+		for i := 0; i < b.N; i++ {
+			_, _, _ = ParseInto(allocsInput, buf)
+		}
+	})
+	// a list nested 1000 deep, which is linear to parse
+	deep := strings.Repeat("[", 1000) + strings.Repeat("]", 1000)
+	b.Run("Deep", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = Parse(deep)
+		}
+	})
+}
+
+func TestValue_JSON(t *testing.T) {
+	var v []Value
+	require.NoError(t, json.Unmarshal([]byte(`[[1,[2]], 3, []]`), &v))
+	require.Len(t, v, 3)
+	require.Equal(t, "[1,[2]]", v[0].String())
+
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, `[[1,[2]],3,[]]`, string(out))
+
+	require.Error(t, json.Unmarshal([]byte(`["a"]`), &v))
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	for _, s := range []string{"[]", "[1,[2,[3,[4,[5,6,7]]]],8,9]", "[[[]]]", " [ -1 , 02 ]", "5", "[1,,2]"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := Parse(s)
+		if err != nil {
+			return
+		}
+		canonical := v.String()
+		again, err := Parse(canonical)
+		require.NoError(t, err, "%q -> %q", s, canonical)
+		require.True(t, v.Equal(again), "%q -> %q", s, canonical)
+		require.Equal(t, canonical, again.String())
+		require.Zero(t, v.Compare(again))
+	})
+}
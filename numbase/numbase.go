@@ -0,0 +1,302 @@
+// Package numbase converts integers to and from positional notation in any base
+// described by a digit alphabet: standard (digits 0 to n-1), bijective (digits
+// 1 to n, with no zero) and balanced (digits centered on zero, like SNAFU's
+// "=-012" for -2 to 2).
+package numbase
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/asymmetricia/aoc22/aoc/mathx"
+)
+
+type Kind int
+
+const (
+	// Standard bases have digits worth 0 to n-1. Negative numbers are written
+	// with a leading '-'.
+	Standard Kind = iota
+	// Bijective bases have digits worth 1 to n, like spreadsheet columns (A, B,
+	// ..., Z, AA, ...). Zero is the empty string. Negative numbers are written
+	// with a leading '-'.
+	Bijective
+	// Balanced bases have an odd number of digits, worth -(n-1)/2 to (n-1)/2,
+	// and can write negative numbers without a sign.
+	Balanced
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Standard:
+		return "standard"
+	case Bijective:
+		return "bijective"
+	case Balanced:
+		return "balanced"
+	}
+	return fmt.Sprintf("(bad kind %d)", int(k))
+}
+
+var (
+	ErrSyntax   = errors.New("invalid syntax")
+	ErrOverflow = errors.New("value out of range")
+)
+
+// Base is a positional numeral system. Its alphabet lists the digits in order
+// of increasing value.
+type Base struct {
+	kind   Kind
+	digits []rune
+	values map[rune]int
+	radix  int
+	// lo is the value of the first digit
+	lo int
+}
+
+// New returns the base of the given kind with the given digits, in order of
+// increasing value.
+func New(kind Kind, alphabet string) (*Base, error) {
+	b := &Base{kind: kind, digits: []rune(alphabet), values: map[rune]int{}}
+	b.radix = len(b.digits)
+	if b.radix < 2 && kind != Bijective || b.radix < 1 {
+		return nil, fmt.Errorf("%v base needs more than %d digit(s)", kind, b.radix)
+	}
+
+	switch kind {
+	case Standard:
+	case Bijective:
+		b.lo = 1
+	case Balanced:
+		if b.radix%2 == 0 {
+			return nil, fmt.Errorf("balanced base needs an odd number of digits, got %d", b.radix)
+		}
+		b.lo = -(b.radix - 1) / 2
+	default:
+		return nil, fmt.Errorf("bad kind %v", kind)
+	}
+
+	for i, d := range b.digits {
+		if _, dup := b.values[d]; dup {
+			return nil, fmt.Errorf("duplicate digit %q", d)
+		}
+		if d == '-' && kind != Balanced {
+			return nil, fmt.Errorf("%v base can't use '-' as a digit", kind)
+		}
+		b.values[d] = b.lo + i
+	}
+	return b, nil
+}
+
+// MustNew is New, but panics on error.
+func MustNew(kind Kind, alphabet string) *Base {
+	b, err := New(kind, alphabet)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var (
+	Binary          = MustNew(Standard, "01")
+	Decimal         = MustNew(Standard, "0123456789")
+	Hex             = MustNew(Standard, "0123456789abcdef")
+	BalancedTernary = MustNew(Balanced, "T01")
+	// Snafu is the balanced base five of AoC 2022 day 25.
+	Snafu = MustNew(Balanced, "=-012")
+	// Columns is the bijective base 26 of spreadsheet column names.
+	Columns = MustNew(Bijective, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+)
+
+func (b *Base) Kind() Kind {
+	return b.kind
+}
+
+func (b *Base) Radix() int {
+	return b.radix
+}
+
+func (b *Base) Alphabet() string {
+	return string(b.digits)
+}
+
+func (b *Base) digit(v int) rune {
+	return b.digits[v-b.lo]
+}
+
+// signed reports whether the base writes negative numbers with a sign.
+func (b *Base) signed() bool {
+	return b.kind != Balanced
+}
+
+// split returns the sign and digit values of s, most significant first.
+func (b *Base) split(s string) (neg bool, vals []int, err error) {
+	rest := s
+	if b.signed() && strings.HasPrefix(rest, "-") {
+		neg = true
+		rest = rest[1:]
+	}
+	if rest == "" && (neg || b.kind != Bijective) {
+		return false, nil, fmt.Errorf("%q: %w", s, ErrSyntax)
+	}
+	for _, r := range rest {
+		v, ok := b.values[r]
+		if !ok {
+			return false, nil, fmt.Errorf("%q: bad digit %q: %w", s, r, ErrSyntax)
+		}
+		vals = append(vals, v)
+	}
+	return neg, vals, nil
+}
+
+// Format returns n written in base b.
+func (b *Base) Format(n int64) string {
+	if n < 0 && b.signed() {
+		// -n may not fit in an int64
+		return b.FormatBig(big.NewInt(n))
+	}
+	if n == 0 && b.kind != Bijective {
+		return string(b.digit(0))
+	}
+
+	var rev []rune
+	radix := int64(b.radix)
+	for n != 0 {
+		// n-d would overflow near the ends of int64, so work from the
+		// remainder instead
+		r := n % radix
+		d := int64(b.lo) + mathx.Mod(r-int64(b.lo), radix)
+		rev = append(rev, b.digit(int(d)))
+		n = n/radix + (r-d)/radix
+	}
+	return reverse(rev)
+}
+
+// Parse reads s, written in base b. It returns an error wrapping ErrSyntax if s
+// isn't a number in base b, or ErrOverflow if it doesn't fit in an int64.
+func (b *Base) Parse(s string) (int64, error) {
+	neg, vals, err := b.split(s)
+	if err != nil {
+		return 0, err
+	}
+	sign := int64(1)
+	if neg {
+		sign = -1
+	}
+
+	// accumulate with the sign already applied, so that the most negative
+	// int64 can be parsed
+	var ret int64
+	for _, v := range vals {
+		var ok1, ok2 bool
+		ret, ok1 = mathx.MulChecked(ret, int64(b.radix))
+		ret, ok2 = mathx.AddChecked(ret, sign*int64(v))
+		if !ok1 || !ok2 {
+			// in a balanced base, later digits can bring a prefix that
+			// doesn't fit back into range
+			n, _ := b.ParseBig(s)
+			if !n.IsInt64() {
+				return 0, fmt.Errorf("%q: %w", s, ErrOverflow)
+			}
+			return n.Int64(), nil
+		}
+	}
+	return ret, nil
+}
+
+// FormatBig returns n written in base b.
+func (b *Base) FormatBig(n *big.Int) string {
+	if n.Sign() == 0 {
+		if b.kind == Bijective {
+			return ""
+		}
+		return string(b.digit(0))
+	}
+
+	sign := ""
+	n = new(big.Int).Set(n)
+	if n.Sign() < 0 && b.signed() {
+		sign = "-"
+		n.Neg(n)
+	}
+
+	var rev []rune
+	radix, lo := big.NewInt(int64(b.radix)), big.NewInt(int64(b.lo))
+	d := new(big.Int)
+	for n.Sign() != 0 {
+		// big.Int.Mod is Euclidean, so d lands in [lo, lo+radix)
+		d.Mod(d.Sub(n, lo), radix).Add(d, lo)
+		rev = append(rev, b.digit(int(d.Int64())))
+		n.Quo(n.Sub(n, d), radix)
+	}
+	return sign + reverse(rev)
+}
+
+// ParseBig reads s, written in base b. It returns an error wrapping ErrSyntax
+// if s isn't a number in base b.
+func (b *Base) ParseBig(s string) (*big.Int, error) {
+	neg, vals, err := b.split(s)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(big.Int)
+	radix, v := big.NewInt(int64(b.radix)), new(big.Int)
+	for _, d := range vals {
+		ret.Mul(ret, radix).Add(ret, v.SetInt64(int64(d)))
+	}
+	if neg {
+		ret.Neg(ret)
+	}
+	return ret, nil
+}
+
+// Add returns the sum of x and y, all written in base b. It adds digit by digit
+// without converting to an integer, so there's no limit on size. Numbers with a
+// sign, which only standard and bijective bases have, are added via big.Int.
+func (b *Base) Add(x, y string) (string, error) {
+	xNeg, xs, err := b.split(x)
+	if err != nil {
+		return "", err
+	}
+	yNeg, ys, err := b.split(y)
+	if err != nil {
+		return "", err
+	}
+	if xNeg || yNeg {
+		xb, _ := b.ParseBig(x)
+		yb, _ := b.ParseBig(y)
+		return b.FormatBig(xb.Add(xb, yb)), nil
+	}
+
+	var rev []rune
+	carry := 0
+	for i := 1; i <= len(xs) || i <= len(ys) || carry != 0; i++ {
+		v := carry
+		if i <= len(xs) {
+			v += xs[len(xs)-i]
+		}
+		if i <= len(ys) {
+			v += ys[len(ys)-i]
+		}
+		d := b.lo + mathx.Mod(v-b.lo, b.radix)
+		rev = append(rev, b.digit(d))
+		carry = (v - d) / b.radix
+	}
+
+	// drop leading zeroes, but not the last digit
+	if b.kind != Bijective {
+		for len(rev) > 1 && rev[len(rev)-1] == b.digit(0) {
+			rev = rev[:len(rev)-1]
+		}
+	}
+	return reverse(rev), nil
+}
+
+func reverse(rs []rune) string {
+	for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+		rs[i], rs[j] = rs[j], rs[i]
+	}
+	return string(rs)
+}
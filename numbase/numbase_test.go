@@ -0,0 +1,176 @@
+package numbase
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnafu(t *testing.T) {
+	// from the day 25 example
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{1, "1"},
+		{2, "2"},
+		{3, "1="},
+		{4, "1-"},
+		{5, "10"},
+		{8, "2="},
+		{10, "20"},
+		{15, "1=0"},
+		{20, "1-0"},
+		{2022, "1=11-2"},
+		{12345, "1-0---0"},
+		{314159265, "1121-1110-1=0"},
+		{4890, "2=-1=0"},
+		{0, "0"},
+		{-3, "-2"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, Snafu.Format(tt.n), "Format(%d)", tt.n)
+		got, err := Snafu.Parse(tt.want)
+		require.NoError(t, err)
+		require.Equal(t, tt.n, got, "Parse(%q)", tt.want)
+	}
+
+	sum := "0"
+	for _, line := range []string{"1=-0-2", "12111", "2=0=", "21", "2=01", "111",
+		"20012", "112", "1=-1=", "1-12", "12", "1=", "122"} {
+		var err error
+		sum, err = Snafu.Add(sum, line)
+		require.NoError(t, err)
+	}
+	require.Equal(t, "2=-1=0", sum)
+}
+
+func TestBases(t *testing.T) {
+	tests := []struct {
+		base *Base
+		n    int64
+		want string
+	}{
+		{Decimal, 0, "0"},
+		{Decimal, -1234, "-1234"},
+		{Decimal, math.MinInt64, "-9223372036854775808"},
+		{Hex, 255, "ff"},
+		{Binary, 10, "1010"},
+		{Columns, 0, ""},
+		{Columns, 1, "A"},
+		{Columns, 26, "Z"},
+		{Columns, 27, "AA"},
+		{Columns, 702, "ZZ"},
+		{Columns, 703, "AAA"},
+		{Columns, -28, "-AB"},
+		{BalancedTernary, 8, "10T"},
+		{BalancedTernary, -8, "T01"},
+		{BalancedTernary, 1e12, "11TT0TT11TTT0111T11T111001"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.base.Format(tt.n), "%v Format(%d)", tt.base.Alphabet(), tt.n)
+		require.Equal(t, tt.want, tt.base.FormatBig(big.NewInt(tt.n)))
+
+		got, err := tt.base.Parse(tt.want)
+		require.NoError(t, err)
+		require.Equal(t, tt.n, got)
+
+		gotBig, err := tt.base.ParseBig(tt.want)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(tt.n), gotBig)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, err := Snafu.Parse("12a")
+	require.ErrorIs(t, err, ErrSyntax)
+	_, err = Snafu.Parse("")
+	require.ErrorIs(t, err, ErrSyntax)
+	_, err = Decimal.Parse("-")
+	require.ErrorIs(t, err, ErrSyntax)
+	_, err = Decimal.Parse("9223372036854775808")
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = Decimal.Parse("-9223372036854775809")
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = Snafu.Add("1", "x")
+	require.ErrorIs(t, err, ErrSyntax)
+
+	for _, bad := range []struct {
+		kind     Kind
+		alphabet string
+	}{
+		{Standard, "0"},
+		{Balanced, "-01="},
+		{Standard, "0-"},
+		{Standard, "001"},
+		{Bijective, ""},
+	} {
+		_, err := New(bad.kind, bad.alphabet)
+		require.Error(t, err, "%v %q", bad.kind, bad.alphabet)
+	}
+}
+
+var fuzzBases = []*Base{Binary, Decimal, Hex, BalancedTernary, Snafu, Columns}
+
+func FuzzRoundTrip(f *testing.F) {
+	for _, n := range []int64{0, 1, -1, 2022, math.MaxInt64, math.MinInt64} {
+		f.Add(n)
+	}
+	f.Fuzz(func(t *testing.T, n int64) {
+		for _, b := range fuzzBases {
+			s := b.Format(n)
+			got, err := b.Parse(s)
+			require.NoError(t, err, "%s in %q", s, b.Alphabet())
+			require.Equal(t, n, got, "%s in %q", s, b.Alphabet())
+
+			require.Equal(t, s, b.FormatBig(big.NewInt(n)))
+			gotBig, err := b.ParseBig(s)
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(n), gotBig)
+		}
+	})
+}
+
+func FuzzAdd(f *testing.F) {
+	f.Add(int64(1), int64(2))
+	f.Add(int64(-7), int64(3))
+	f.Add(int64(math.MaxInt64), int64(math.MaxInt64))
+	f.Add(int64(math.MinInt64), int64(math.MinInt64))
+	f.Fuzz(func(t *testing.T, x, y int64) {
+		want := new(big.Int).Add(big.NewInt(x), big.NewInt(y))
+		for _, b := range fuzzBases {
+			got, err := b.Add(b.Format(x), b.Format(y))
+			require.NoError(t, err)
+			require.Equal(t, b.FormatBig(want), got, "%d + %d in %q", x, y, b.Alphabet())
+		}
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{"", "0", "1=-0-2", "-AB", "T01", "ff", "00012"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		for _, b := range fuzzBases {
+			n, err := b.ParseBig(s)
+			if err != nil {
+				continue
+			}
+			// formatting gives the canonical form, which parses the same
+			again, err := b.ParseBig(b.FormatBig(n))
+			require.NoError(t, err)
+			require.Equal(t, n, again)
+
+			if n.IsInt64() {
+				small, err := b.Parse(s)
+				require.NoError(t, err)
+				require.Equal(t, n.Int64(), small)
+			} else {
+				_, err := b.Parse(s)
+				require.ErrorIs(t, err, ErrOverflow)
+			}
+		}
+	})
+}
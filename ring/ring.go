@@ -0,0 +1,283 @@
+// Package ring provides a circular sequence container with stable element
+// handles. It's backed by an implicit treap (a balanced order-statistic tree),
+// so finding an element's position, indexing, inserting, removing and moving
+// are all O(log n).
+package ring
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Element is a handle to a value in a Ring. It stays valid, and keeps pointing
+// at the same value, as the ring is rearranged around it.
+type Element[T any] struct {
+	Value T
+
+	left, right, parent *Element[T]
+	prio                uint32
+	size                int
+}
+
+func size[T any](e *Element[T]) int {
+	if e == nil {
+		return 0
+	}
+	return e.size
+}
+
+func (e *Element[T]) update() {
+	e.size = 1 + size(e.left) + size(e.right)
+	if e.left != nil {
+		e.left.parent = e
+	}
+	if e.right != nil {
+		e.right.parent = e
+	}
+}
+
+// Ring is a circular sequence of values. Positions are counted from an
+// arbitrary but fixed starting point, which only matters for Index, At and
+// Values; everything else is relative. The zero value is an empty ring.
+type Ring[T any] struct {
+	root *Element[T]
+	seed uint32
+}
+
+// New returns a Ring holding the values, in order.
+func New[T any](values ...T) *Ring[T] {
+	r := &Ring[T]{}
+	for _, v := range values {
+		r.PushBack(v)
+	}
+	return r
+}
+
+// Len returns the number of elements in the ring.
+func (r *Ring[T]) Len() int {
+	return size(r.root)
+}
+
+// rand is a xorshift generator for treap priorities; the tree's shape doesn't
+// affect results, but a private generator keeps it reproducible.
+func (r *Ring[T]) rand() uint32 {
+	if r.seed == 0 {
+		r.seed = 2463534242
+	}
+	r.seed ^= r.seed << 13
+	r.seed ^= r.seed >> 17
+	r.seed ^= r.seed << 5
+	return r.seed
+}
+
+// split divides t into its first k elements and the rest.
+func split[T any](t *Element[T], k int) (a, b *Element[T]) {
+	if t == nil {
+		return nil, nil
+	}
+	if size(t.left) >= k {
+		a, t.left = split(t.left, k)
+		t.update()
+		b = t
+	} else {
+		t.right, b = split(t.right, k-size(t.left)-1)
+		t.update()
+		a = t
+	}
+	if a != nil {
+		a.parent = nil
+	}
+	if b != nil {
+		b.parent = nil
+	}
+	return a, b
+}
+
+func merge[T any](a, b *Element[T]) *Element[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.prio > b.prio {
+		a.right = merge(a.right, b)
+		a.update()
+		return a
+	}
+	b.left = merge(a, b.left)
+	b.update()
+	return b
+}
+
+func (r *Ring[T]) setRoot(e *Element[T]) {
+	r.root = e
+	if e != nil {
+		e.parent = nil
+	}
+}
+
+// insert puts e at position i, so that it has i elements before it.
+func (r *Ring[T]) insert(e *Element[T], i int) {
+	e.left, e.right, e.parent, e.size = nil, nil, nil, 1
+	a, b := split(r.root, i)
+	r.setRoot(merge(merge(a, e), b))
+}
+
+// PushBack adds v at the end of the sequence, i.e. just before the element at
+// position 0.
+func (r *Ring[T]) PushBack(v T) *Element[T] {
+	e := &Element[T]{Value: v, prio: r.rand()}
+	r.insert(e, r.Len())
+	return e
+}
+
+// InsertAfter adds v immediately after e.
+func (r *Ring[T]) InsertAfter(e *Element[T], v T) *Element[T] {
+	n := &Element[T]{Value: v, prio: r.rand()}
+	r.insert(n, r.Index(e)+1)
+	return n
+}
+
+// InsertBefore adds v immediately before e.
+func (r *Ring[T]) InsertBefore(e *Element[T], v T) *Element[T] {
+	n := &Element[T]{Value: v, prio: r.rand()}
+	r.insert(n, r.Index(e))
+	return n
+}
+
+// Remove removes e from the ring. e must not be used with the ring afterwards.
+func (r *Ring[T]) Remove(e *Element[T]) {
+	i := r.Index(e)
+	a, rest := split(r.root, i)
+	_, b := split(rest, 1)
+	r.setRoot(merge(a, b))
+	e.left, e.right, e.parent = nil, nil, nil
+}
+
+// Index returns the position of e.
+func (r *Ring[T]) Index(e *Element[T]) int {
+	i := size(e.left)
+	for e.parent != nil {
+		if e.parent.right == e {
+			i += size(e.parent.left) + 1
+		}
+		e = e.parent
+	}
+	return i
+}
+
+// At returns the element at position i, modulo the length of the ring, so
+// negative positions count back from the end. It returns nil if the ring is
+// empty.
+func (r *Ring[T]) At(i int) *Element[T] {
+	if r.root == nil {
+		return nil
+	}
+	i = mod(i, r.Len())
+	t := r.root
+	for {
+		ls := size(t.left)
+		switch {
+		case i < ls:
+			t = t.left
+		case i == ls:
+			return t
+		default:
+			i -= ls + 1
+			t = t.right
+		}
+	}
+}
+
+// Nth returns the element n steps after e, wrapping around; negative n counts
+// backwards.
+func (r *Ring[T]) Nth(e *Element[T], n int) *Element[T] {
+	return r.At(r.Index(e) + n)
+}
+
+func (r *Ring[T]) Next(e *Element[T]) *Element[T] {
+	return r.Nth(e, 1)
+}
+
+func (r *Ring[T]) Prev(e *Element[T]) *Element[T] {
+	return r.Nth(e, -1)
+}
+
+// MoveBy moves e n steps forward (or back, if n is negative) past its
+// neighbors. Since e isn't one of its own neighbors, moving len-1 steps brings
+// it back to where it started, so n is reduced modulo len-1 and any n is O(log
+// n).
+func (r *Ring[T]) MoveBy(e *Element[T], n int) {
+	l := r.Len()
+	if l <= 2 {
+		// every arrangement of two elements is the same ring
+		return
+	}
+	i := r.Index(e)
+	r.Remove(e)
+	r.insert(e, mod(i+n, l-1))
+}
+
+// Each calls f for each element, starting at from and going forwards around
+// the ring once, stopping early if f returns true. If from is nil, it starts
+// at position 0.
+func (r *Ring[T]) Each(from *Element[T], f func(*Element[T]) (stop bool)) {
+	start := 0
+	if from != nil {
+		start = r.Index(from)
+	}
+	var walk func(t *Element[T]) bool
+	walk = func(t *Element[T]) bool {
+		if t == nil {
+			return false
+		}
+		return walk(t.left) || f(t) || walk(t.right)
+	}
+	a, b := split(r.root, start)
+	// walk the elements from start to the end, then from 0 to start
+	if !walk(b) {
+		walk(a)
+	}
+	r.setRoot(merge(a, b))
+}
+
+// Values returns the values in the ring, starting at from (or position 0, if
+// from is nil).
+func (r *Ring[T]) Values(from *Element[T]) []T {
+	ret := make([]T, 0, r.Len())
+	r.Each(from, func(e *Element[T]) bool {
+		ret = append(ret, e.Value)
+		return false
+	})
+	return ret
+}
+
+// Find returns the first element from position 0 whose value matches, or nil.
+func (r *Ring[T]) Find(match func(T) bool) *Element[T] {
+	var ret *Element[T]
+	r.Each(nil, func(e *Element[T]) bool {
+		if match(e.Value) {
+			ret = e
+			return true
+		}
+		return false
+	})
+	return ret
+}
+
+func (r *Ring[T]) String() string {
+	parts := make([]string, 0, r.Len())
+	for _, v := range r.Values(nil) {
+		parts = append(parts, fmt.Sprint(v))
+	}
+	return "[ " + strings.Join(parts, ", ") + " ]"
+}
+
+func mod(a, b int) int {
+	a %= b
+	if a < 0 {
+		a += b
+	}
+	return a
+}
@@ -0,0 +1,94 @@
+package ring
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mix implements AoC 2022 day 20.
+func mix(nums []int, key, rounds int) int {
+	r := &Ring[int]{}
+	var elems []*Element[int]
+	for _, n := range nums {
+		elems = append(elems, r.PushBack(n*key))
+	}
+	for i := 0; i < rounds; i++ {
+		for _, e := range elems {
+			r.MoveBy(e, e.Value)
+		}
+	}
+	zero := r.Find(func(v int) bool { return v == 0 })
+	return r.Nth(zero, 1000).Value + r.Nth(zero, 2000).Value + r.Nth(zero, 3000).Value
+}
+
+func TestRing_Mix(t *testing.T) {
+	nums := []int{1, 2, -3, 3, -2, 0, 4}
+	require.Equal(t, 3, mix(nums, 1, 1))
+	require.Equal(t, 1623178306, mix(nums, 811589153, 10))
+}
+
+func TestRing_MoveBy(t *testing.T) {
+	r := New(4, -2, 5, 6, 7, 8, 9)
+	r.MoveBy(r.At(1), -2)
+	require.Equal(t, []int{4, 5, 6, 7, 8, -2, 9}, r.Values(nil))
+
+	// in a ring of three, moving 5 steps is the same as moving 1
+	r = New(1, 2, 3)
+	one := r.At(0)
+	r.MoveBy(one, 5)
+	require.Equal(t, []int{1, 3, 2}, r.Values(one))
+}
+
+func TestRing_Handles(t *testing.T) {
+	r := New[string]()
+	a := r.PushBack("a")
+	c := r.PushBack("c")
+	b := r.InsertAfter(a, "b")
+	z := r.InsertBefore(a, "z")
+	require.Equal(t, "[ z, a, b, c ]", r.String())
+	require.Equal(t, []string{"b", "c", "z", "a"}, r.Values(b))
+
+	require.Equal(t, 3, r.Index(c))
+	require.Equal(t, z, r.Next(c))
+	require.Equal(t, c, r.Prev(z))
+	require.Equal(t, a, r.Nth(c, -6))
+	require.Equal(t, c, r.At(-1))
+
+	r.Remove(a)
+	require.Equal(t, []string{"z", "b", "c"}, r.Values(nil))
+	require.Equal(t, 1, r.Index(b))
+	require.Equal(t, 3, r.Len())
+}
+
+// TestRing_Random compares the ring against a naive slice-based one.
+func TestRing_Random(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	r := &Ring[int]{}
+	var elems []*Element[int]
+	var naive []int
+	for i := 0; i < 200; i++ {
+		elems = append(elems, r.PushBack(i))
+		naive = append(naive, i)
+	}
+
+	for i := 0; i < 2000; i++ {
+		j := rng.Intn(len(elems))
+		n := rng.Intn(2001) - 1000
+
+		idx := 0
+		for naive[idx] != elems[j].Value {
+			idx++
+		}
+		require.Equal(t, idx, r.Index(elems[j]))
+
+		v := naive[idx]
+		naive = append(naive[:idx], naive[idx+1:]...)
+		to := mod(idx+n, len(naive))
+		naive = append(naive[:to], append([]int{v}, naive[to:]...)...)
+
+		r.MoveBy(elems[j], n)
+		require.Equal(t, naive, r.Values(nil))
+	}
+}
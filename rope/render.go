@@ -0,0 +1,91 @@
+package rope
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/canvas"
+	"github.com/asymmetricia/aoc22/coord"
+)
+
+// knotRune returns the label of knot i of n: H for the head, T for the tail,
+// and digits in between.
+func knotRune(i, n int) rune {
+	switch {
+	case i == 0:
+		return 'H'
+	case i == n-1:
+		return 'T'
+	case i < 10:
+		return rune('0' + i)
+	}
+	return '*'
+}
+
+// Canvas draws the part of the plane within bounds, below a box counting the
+// steps taken: the tail's trail as '#', cells other knots have visited as '.',
+// and the knots themselves, colored from the head to the tail. Passing the
+// final Bounds of a complete run keeps successive frames lined up.
+func (r *Rope) Canvas(bounds image.Rectangle) *canvas.Canvas {
+	cnv := &canvas.Canvas{}
+	canvas.TextBox{
+		Title:      []rune("Rope Bridge"),
+		Body:       []rune(fmt.Sprintf("step %d", r.Steps)),
+		Footer:     []rune(fmt.Sprintf("tail visited %d", len(r.TailVisited()))),
+		BodyColor:  aoc.TolVibrantOrange,
+		TitleColor: aoc.TolVibrantTeal,
+		FrameColor: aoc.TolVibrantGrey,
+		Width:      aoc.Max(bounds.Dx()-2, 20),
+	}.On(cnv)
+
+	const top = 3
+	plot := func(c coord.Coord, cell canvas.Cell) {
+		if !(image.Point{X: c.X, Y: c.Y}).In(bounds) {
+			return
+		}
+		cnv.Set(c.X-bounds.Min.X, top+c.Y-bounds.Min.Y, cell)
+	}
+
+	for i := 1; i < len(r.Visited)-1; i++ {
+		for c := range r.Visited[i] {
+			plot(c, canvas.Cell{Color: aoc.TolVibrantGrey, Value: '.'})
+		}
+	}
+	for c := range r.TailVisited() {
+		plot(c, canvas.Cell{Color: aoc.TolVibrantBlue, Value: '#'})
+	}
+	// draw from the tail forward, so knots in front cover the ones behind
+	for i := len(r.Knots) - 1; i >= 0; i-- {
+		plot(r.Knots[i], canvas.Cell{
+			Color: aoc.TolScale(0, aoc.Max(len(r.Knots)-1, 1), i),
+			Value: knotRune(i, len(r.Knots)),
+		})
+	}
+	return cnv
+}
+
+// Recorder captures frames of a rope as it moves. Add its Hook to the rope's
+// Hooks.
+type Recorder struct {
+	// Every is how many steps to take between frames; 0 means 1.
+	Every int
+	// Bounds is the part of the plane to draw; the empty rectangle means the
+	// rope's current Bounds.
+	Bounds image.Rectangle
+
+	// Frames are the frames captured so far, suitable for canvas.RenderGif.
+	Frames []*canvas.Canvas
+}
+
+// Hook captures a frame of r if it's due.
+func (rec *Recorder) Hook(r *Rope) {
+	if rec.Every > 1 && r.Steps%rec.Every != 0 {
+		return
+	}
+	bounds := rec.Bounds
+	if bounds.Empty() {
+		bounds = r.Bounds()
+	}
+	rec.Frames = append(rec.Frames, r.Canvas(bounds))
+}
@@ -0,0 +1,207 @@
+// Package rope simulates ropes of knots, like AoC 2022 day 9's: the head is
+// moved around, and every other knot follows the one in front of it according
+// to a Rule.
+package rope
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/asymmetricia/aoc22/coord"
+	"github.com/asymmetricia/aoc22/set"
+)
+
+// A Rule returns where follower moves to after leader has moved. Applying a
+// rule twice must give the same result as applying it once; Step relies on it
+// to stop early once a knot doesn't move.
+type Rule func(leader, follower coord.Coord) coord.Coord
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	}
+	return 0
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// Chebyshev is the AoC rule: a knot stays put while it's touching its leader,
+// including diagonally, and otherwise takes one step straight or diagonally
+// toward it.
+func Chebyshev(leader, follower coord.Coord) coord.Coord {
+	dx, dy := leader.X-follower.X, leader.Y-follower.Y
+	if abs(dx) <= 1 && abs(dy) <= 1 {
+		return follower
+	}
+	return coord.C(follower.X+sign(dx), follower.Y+sign(dy))
+}
+
+// Orthogonal forbids diagonals: a knot stays put while it overlaps or is
+// orthogonally adjacent to its leader, and otherwise takes one step north,
+// south, east or west toward it, along the axis it's further away on (east or
+// west, on a tie).
+func Orthogonal(leader, follower coord.Coord) coord.Coord {
+	dx, dy := leader.X-follower.X, leader.Y-follower.Y
+	if abs(dx)+abs(dy) <= 1 {
+		return follower
+	}
+	if abs(dx) >= abs(dy) {
+		return coord.C(follower.X+sign(dx), follower.Y)
+	}
+	return coord.C(follower.X, follower.Y+sign(dy))
+}
+
+// Rope is a chain of knots; Knots[0] is the head.
+type Rope struct {
+	Knots []coord.Coord
+	Rule  Rule
+
+	// Visited holds every position each knot has been in.
+	Visited []set.Set[coord.Coord]
+
+	// Hooks are called after every step.
+	Hooks []func(r *Rope)
+
+	// Steps is the number of steps taken so far.
+	Steps int
+}
+
+// New returns a rope of n knots, all at start, which follow the rule; a nil
+// rule means Chebyshev.
+func New(n int, start coord.Coord, rule Rule) *Rope {
+	if n < 1 {
+		panic(fmt.Sprintf("rope.New: need at least one knot, got %d", n))
+	}
+	if rule == nil {
+		rule = Chebyshev
+	}
+	r := &Rope{
+		Knots:   make([]coord.Coord, n),
+		Rule:    rule,
+		Visited: make([]set.Set[coord.Coord], n),
+	}
+	for i := range r.Knots {
+		r.Knots[i] = start
+		r.Visited[i] = set.Set[coord.Coord]{start: true}
+	}
+	return r
+}
+
+func (r *Rope) Head() coord.Coord {
+	return r.Knots[0]
+}
+
+func (r *Rope) Tail() coord.Coord {
+	return r.Knots[len(r.Knots)-1]
+}
+
+// TailVisited returns the positions the tail has been in.
+func (r *Rope) TailVisited() set.Set[coord.Coord] {
+	return r.Visited[len(r.Visited)-1]
+}
+
+// Step moves the head one step in direction d, and then each knot in turn
+// according to the rule.
+func (r *Rope) Step(d coord.Direction) {
+	r.Knots[0] = r.Knots[0].Move(d)
+	r.Visited[0].Add(r.Knots[0])
+	for i := 1; i < len(r.Knots); i++ {
+		next := r.Rule(r.Knots[i-1], r.Knots[i])
+		if next == r.Knots[i] {
+			// nothing further back can move either
+			break
+		}
+		r.Knots[i] = next
+		r.Visited[i].Add(next)
+	}
+	r.Steps++
+	for _, hook := range r.Hooks {
+		hook(r)
+	}
+}
+
+// Motion is a number of steps in one direction.
+type Motion struct {
+	Dir coord.Direction
+	N   int
+}
+
+var motionDirections = map[string]coord.Direction{
+	"U": coord.North,
+	"D": coord.South,
+	"L": coord.West,
+	"R": coord.East,
+}
+
+// ParseMotion reads a motion like "R 4": U, D, L or R, and a number of steps.
+func ParseMotion(s string) (Motion, error) {
+	f := strings.Fields(s)
+	if len(f) != 2 {
+		return Motion{}, fmt.Errorf("expected direction and count, got %q", s)
+	}
+	d, ok := motionDirections[f[0]]
+	if !ok {
+		return Motion{}, fmt.Errorf("bad direction %q in %q", f[0], s)
+	}
+	n, err := strconv.Atoi(f[1])
+	if err != nil || n < 0 {
+		return Motion{}, fmt.Errorf("bad count %q in %q", f[1], s)
+	}
+	return Motion{d, n}, nil
+}
+
+// Parse reads motions, one per line.
+func Parse(rd io.Reader) ([]Motion, error) {
+	var ret []Motion
+	scanner := bufio.NewScanner(rd)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		m, err := ParseMotion(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		ret = append(ret, m)
+	}
+	return ret, scanner.Err()
+}
+
+// Apply takes the steps of each motion in turn.
+func (r *Rope) Apply(motions ...Motion) {
+	for _, m := range motions {
+		for i := 0; i < m.N; i++ {
+			r.Step(m.Dir)
+		}
+	}
+}
+
+// Bounds returns the smallest rectangle containing every position any knot
+// has visited.
+func (r *Rope) Bounds() image.Rectangle {
+	var ret image.Rectangle
+	for _, visited := range r.Visited {
+		for c := range visited {
+			cell := image.Rect(c.X, c.Y, c.X+1, c.Y+1)
+			if ret.Empty() {
+				ret = cell
+			} else {
+				ret = ret.Union(cell)
+			}
+		}
+	}
+	return ret
+}
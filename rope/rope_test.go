@@ -0,0 +1,140 @@
+package rope
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/asymmetricia/aoc22/coord"
+)
+
+const example = `R 4
+U 4
+L 3
+D 1
+R 4
+D 1
+L 5
+R 2
+`
+
+const largerExample = `R 5
+U 8
+L 8
+D 3
+R 17
+D 10
+L 25
+U 20
+`
+
+func TestRope_Apply(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		knots int
+		want  int
+	}{
+		{"part 1", example, 2, 13},
+		{"part 2", example, 10, 1},
+		{"part 2, larger", largerExample, 10, 36},
+		{"single knot", example, 1, 21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			motions, err := Parse(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			r := New(tt.knots, coord.C(0, 0), nil)
+			r.Apply(motions...)
+			require.Len(t, r.TailVisited(), tt.want)
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		leader   coord.Coord
+		follower coord.Coord
+		want     coord.Coord
+	}{
+		{"chebyshev touching", Chebyshev, coord.C(1, 1), coord.C(0, 0), coord.C(0, 0)},
+		{"chebyshev straight", Chebyshev, coord.C(2, 0), coord.C(0, 0), coord.C(1, 0)},
+		{"chebyshev diagonal", Chebyshev, coord.C(2, 1), coord.C(0, 0), coord.C(1, 1)},
+		{"chebyshev far diagonal", Chebyshev, coord.C(-2, -2), coord.C(0, 0), coord.C(-1, -1)},
+		{"orthogonal adjacent", Orthogonal, coord.C(0, 1), coord.C(0, 0), coord.C(0, 0)},
+		{"orthogonal diagonal", Orthogonal, coord.C(1, 1), coord.C(0, 0), coord.C(1, 0)},
+		{"orthogonal longer axis", Orthogonal, coord.C(1, -2), coord.C(0, 0), coord.C(0, -1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.rule(tt.leader, tt.follower))
+		})
+	}
+}
+
+func TestRope_Orthogonal(t *testing.T) {
+	r := New(3, coord.C(0, 0), Orthogonal)
+	r.Apply(Motion{coord.East, 2}, Motion{coord.South, 2})
+	require.Equal(t, []coord.Coord{coord.C(2, 2), coord.C(2, 1), coord.C(2, 0)}, r.Knots)
+	for i := 1; i < len(r.Knots); i++ {
+		require.LessOrEqual(t, r.Knots[i].TaxiDistance(r.Knots[i-1]), 1)
+	}
+}
+
+func TestParseMotion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Motion
+		wantErr bool
+	}{
+		{"R 4", Motion{coord.East, 4}, false},
+		{"U 12", Motion{coord.North, 12}, false},
+		{"X 4", Motion{}, true},
+		{"R", Motion{}, true},
+		{"R -1", Motion{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMotion(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := Parse(strings.NewReader("R 1\nQ 2\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 2")
+}
+
+func TestRecorder(t *testing.T) {
+	motions, err := Parse(strings.NewReader(example))
+	require.NoError(t, err)
+
+	r := New(2, coord.C(0, 0), nil)
+	r.Apply(motions...)
+	bounds := r.Bounds()
+	require.Equal(t, image.Rect(0, -4, 6, 1), bounds)
+
+	r = New(2, coord.C(0, 0), nil)
+	rec := &Recorder{Every: 4, Bounds: bounds}
+	r.Hooks = append(r.Hooks, rec.Hook)
+	r.Apply(motions...)
+	require.Len(t, rec.Frames, 24/4)
+
+	// the last frame shows the head, the tail and the trail
+	last := rec.Frames[len(rec.Frames)-1]
+	cell := func(c coord.Coord) rune {
+		return last.Pix[3+c.Y-bounds.Min.Y][c.X-bounds.Min.X].Value
+	}
+	require.Equal(t, 'H', cell(r.Head()))
+	require.Equal(t, 'T', cell(r.Tail()))
+	require.Equal(t, '#', cell(coord.C(0, 0)))
+}
@@ -0,0 +1,50 @@
+package rps
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/canvas"
+)
+
+// outcomeColors frame each outcome's row of the panel.
+var outcomeColors = [3]color.Color{
+	Lose: aoc.TolVibrantRed,
+	Draw: aoc.TolVibrantOrange,
+	Win:  aoc.TolVibrantTeal,
+}
+
+// throwColors color the counts in each throw's column, cycling if there are
+// more throws.
+var throwColors = []color.Color{
+	aoc.TolVibrantMagenta,
+	color.White,
+	aoc.TolVibrantCyan,
+	aoc.TolVibrantBlue,
+	aoc.TolVibrantGrey,
+}
+
+// Panel draws t onto cnv as a grid of stats boxes, with its top-left corner at
+// top, left: a row for each outcome and a column for each throw, each box
+// showing in block letters how many times that throw had that outcome. It
+// returns the area it drew on.
+func (t *Tally) Panel(cnv *canvas.Canvas, top, left int) image.Rectangle {
+	const boxWidth = aoc.GlyphWidth*3 + 2
+	for o := Lose; o <= Win; o++ {
+		for th := range t.Counts {
+			canvas.TextBox{
+				Top:        top + int(o)*aoc.LineHeight,
+				Left:       left + th*boxWidth,
+				Title:      []rune(t.Game.Name(Throw(th))),
+				Body:       []rune(fmt.Sprintf("%3d", t.Counts[th][o])),
+				BodyBlock:  true,
+				Footer:     []rune(o.String()),
+				FrameColor: outcomeColors[o],
+				BodyColor:  throwColors[th%len(throwColors)],
+			}.On(cnv)
+		}
+	}
+	return image.Rect(left, top, left+len(t.Counts)*boxWidth, top+3*aoc.LineHeight)
+}
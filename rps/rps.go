@@ -0,0 +1,244 @@
+// Package rps plays cyclic dominance games: rock-paper-scissors, like AoC 2022
+// day 2, and its variants with more throws, like rock-paper-scissors-lizard-
+// Spock.
+package rps
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Throw is a move in a Game, numbered from 0.
+type Throw int
+
+// Outcome is the result of a round, for one player.
+type Outcome int
+
+const (
+	Lose Outcome = iota
+	Draw
+	Win
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Lose:
+		return "lose"
+	case Draw:
+		return "draw"
+	case Win:
+		return "win"
+	}
+	return fmt.Sprintf("(bad outcome %d)", int(o))
+}
+
+// Game is a game of n throws arranged in a cycle, where each throw beats the
+// (n-1)/2 throws before it and loses to the (n-1)/2 after it. The number of
+// throws is odd, so any two different throws have a winner.
+type Game struct {
+	// Names are the names of the throws, in order.
+	Names []string
+}
+
+var (
+	RockPaperScissors = Game{Names: []string{"rock", "paper", "scissors"}}
+	// RockPaperScissorsLizardSpock is ordered so that it's cyclic: Spock
+	// smashes scissors and vaporizes rock, and so on.
+	RockPaperScissorsLizardSpock = Game{Names: []string{"rock", "Spock", "paper", "lizard", "scissors"}}
+)
+
+// ErrEvenGame is returned for a Game with an even number of throws, which
+// would have pairs of throws that beat neither of each other.
+var ErrEvenGame = errors.New("game needs an odd number of throws")
+
+// Validate checks that g is playable.
+func (g Game) Validate() error {
+	if len(g.Names)%2 == 0 {
+		return fmt.Errorf("%w, has %d", ErrEvenGame, len(g.Names))
+	}
+	return nil
+}
+
+// N returns the number of throws.
+func (g Game) N() int {
+	return len(g.Names)
+}
+
+// Name returns the name of throw t.
+func (g Game) Name(t Throw) string {
+	if t < 0 || int(t) >= len(g.Names) {
+		return fmt.Sprintf("(bad throw %d)", int(t))
+	}
+	return g.Names[t]
+}
+
+func (g Game) mod(t Throw) Throw {
+	n := Throw(len(g.Names))
+	return (t%n + n) % n
+}
+
+// Play returns the outcome for the player throwing mine against theirs.
+func (g Game) Play(mine, theirs Throw) Outcome {
+	d := int(g.mod(mine - theirs))
+	switch {
+	case d == 0:
+		return Draw
+	case d <= len(g.Names)/2:
+		return Win
+	}
+	return Lose
+}
+
+// For returns a throw that gets outcome o against theirs. When several do,
+// it's the nearest one in the cycle: the throw just after theirs to win, and
+// just before to lose.
+func (g Game) For(theirs Throw, o Outcome) Throw {
+	switch o {
+	case Win:
+		return g.mod(theirs + 1)
+	case Lose:
+		return g.mod(theirs - 1)
+	}
+	return theirs
+}
+
+// Round is one round of a game.
+type Round struct {
+	Theirs, Mine Throw
+	Outcome      Outcome
+}
+
+// Meaning is what the second column of a strategy guide means.
+type Meaning int
+
+const (
+	// MeansThrow means the second column is the throw to make.
+	MeansThrow Meaning = iota
+	// MeansOutcome means the second column is the outcome to aim for; the
+	// throw to make is worked out with Game.For.
+	MeansOutcome
+)
+
+// Guide decodes a strategy guide: lines of two codes, the opponent's throw and
+// then either our throw or our outcome.
+type Guide struct {
+	Game Game
+	// Theirs holds the codes for the opponent's throws, in order.
+	Theirs string
+	// Mine holds the codes for the second column, in order: for each throw,
+	// if Meaning is MeansThrow, or for Lose, Draw and Win if it's
+	// MeansOutcome.
+	Mine    string
+	Meaning Meaning
+}
+
+// AoCGuide returns the guide of AoC 2022 day 2, where A, B and C are the
+// opponent's rock, paper and scissors, and X, Y and Z mean the given meaning.
+func AoCGuide(m Meaning) Guide {
+	return Guide{Game: RockPaperScissors, Theirs: "ABC", Mine: "XYZ", Meaning: m}
+}
+
+// ParseLine decodes a line like "A Y".
+func (gd Guide) ParseLine(s string) (Round, error) {
+	f := strings.Fields(s)
+	if len(f) != 2 {
+		return Round{}, fmt.Errorf("expected two codes, got %q", s)
+	}
+	theirs := strings.Index(gd.Theirs, f[0])
+	if len(f[0]) != 1 || theirs < 0 || theirs >= gd.Game.N() {
+		return Round{}, fmt.Errorf("bad code %q for their throw", f[0])
+	}
+	mine := strings.Index(gd.Mine, f[1])
+	if len(f[1]) != 1 || mine < 0 {
+		return Round{}, fmt.Errorf("bad code %q for the second column", f[1])
+	}
+
+	r := Round{Theirs: Throw(theirs)}
+	switch gd.Meaning {
+	case MeansThrow:
+		if mine >= gd.Game.N() {
+			return Round{}, fmt.Errorf("bad code %q for my throw", f[1])
+		}
+		r.Mine = Throw(mine)
+		r.Outcome = gd.Game.Play(r.Mine, r.Theirs)
+	case MeansOutcome:
+		if mine > int(Win) {
+			return Round{}, fmt.Errorf("bad code %q for the outcome", f[1])
+		}
+		r.Outcome = Outcome(mine)
+		r.Mine = gd.Game.For(r.Theirs, r.Outcome)
+	default:
+		return Round{}, fmt.Errorf("bad meaning %d", int(gd.Meaning))
+	}
+	return r, nil
+}
+
+// Parse decodes a guide, one round per line.
+func (gd Guide) Parse(r io.Reader) ([]Round, error) {
+	if err := gd.Game.Validate(); err != nil {
+		return nil, err
+	}
+	var ret []Round
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		round, err := gd.ParseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		ret = append(ret, round)
+	}
+	return ret, scanner.Err()
+}
+
+// AoCScore scores a round as AoC does: 1 for throwing the first throw, 2 for
+// the second and so on, plus 0 for losing, 3 for a draw and 6 for winning.
+func AoCScore(r Round) int {
+	return int(r.Mine) + 1 + 3*int(r.Outcome)
+}
+
+// Tally adds up the rounds played, and how often each throw had each outcome.
+type Tally struct {
+	Game  Game
+	Score func(Round) int
+
+	// Counts[t][o] is the number of rounds we threw t with outcome o.
+	Counts [][3]int
+	Rounds int
+	Total  int
+}
+
+// NewTally returns an empty tally for g, which scores rounds with score; nil
+// means AoCScore.
+func NewTally(g Game, score func(Round) int) *Tally {
+	if score == nil {
+		score = AoCScore
+	}
+	return &Tally{Game: g, Score: score, Counts: make([][3]int, g.N())}
+}
+
+// Add records r, and returns its score.
+func (t *Tally) Add(r Round) int {
+	s := t.Score(r)
+	t.Counts[r.Mine][r.Outcome]++
+	t.Rounds++
+	t.Total += s
+	return s
+}
+
+// Outcomes returns the number of rounds with each outcome, indexed by Outcome.
+func (t *Tally) Outcomes() [3]int {
+	var ret [3]int
+	for _, counts := range t.Counts {
+		for o, n := range counts {
+			ret[o] += n
+		}
+	}
+	return ret
+}
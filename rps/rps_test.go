@@ -0,0 +1,108 @@
+package rps
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/asymmetricia/aoc22/canvas"
+)
+
+const example = `A Y
+B X
+C Z
+`
+
+func TestGame_Play(t *testing.T) {
+	g := RockPaperScissors
+	rock, paper, scissors := Throw(0), Throw(1), Throw(2)
+	tests := []struct {
+		mine, theirs Throw
+		want         Outcome
+	}{
+		{rock, scissors, Win},
+		{rock, paper, Lose},
+		{paper, rock, Win},
+		{scissors, paper, Win},
+		{scissors, rock, Lose},
+		{paper, paper, Draw},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, g.Play(tt.mine, tt.theirs), "%s vs %s", g.Name(tt.mine), g.Name(tt.theirs))
+	}
+}
+
+// TestGame_Symmetric checks that in every game, each throw beats exactly half
+// the others and For agrees with Play.
+func TestGame_Symmetric(t *testing.T) {
+	for _, g := range []Game{RockPaperScissors, RockPaperScissorsLizardSpock, {Names: make([]string, 7)}} {
+		require.NoError(t, g.Validate())
+		for a := Throw(0); int(a) < g.N(); a++ {
+			wins := 0
+			for b := Throw(0); int(b) < g.N(); b++ {
+				o := g.Play(a, b)
+				require.Equal(t, Win-o, g.Play(b, a))
+				if o == Win {
+					wins++
+				}
+			}
+			require.Equal(t, g.N()/2, wins)
+			for o := Lose; o <= Win; o++ {
+				require.Equal(t, o, g.Play(g.For(a, o), a))
+			}
+		}
+	}
+
+	spock := RockPaperScissorsLizardSpock
+	require.Equal(t, Win, spock.Play(1, 4), "Spock smashes scissors")
+	require.Equal(t, Win, spock.Play(3, 1), "lizard poisons Spock")
+
+	require.True(t, errors.Is(Game{Names: []string{"a", "b"}}.Validate(), ErrEvenGame))
+}
+
+func TestGuide(t *testing.T) {
+	tests := []struct {
+		meaning Meaning
+		want    int
+		rounds  []Round
+	}{
+		{MeansThrow, 15, []Round{{0, 1, Win}, {1, 0, Lose}, {2, 2, Draw}}},
+		{MeansOutcome, 12, []Round{{0, 0, Draw}, {1, 0, Lose}, {2, 0, Win}}},
+	}
+	for _, tt := range tests {
+		rounds, err := AoCGuide(tt.meaning).Parse(strings.NewReader(example))
+		require.NoError(t, err)
+		require.Equal(t, tt.rounds, rounds)
+
+		tally := NewTally(RockPaperScissors, nil)
+		for _, r := range rounds {
+			tally.Add(r)
+		}
+		require.Equal(t, tt.want, tally.Total)
+		require.Equal(t, 3, tally.Rounds)
+		require.Equal(t, [3]int{1, 1, 1}, tally.Outcomes())
+	}
+
+	for _, bad := range []string{"A", "D X", "A W", "AA X"} {
+		_, err := AoCGuide(MeansThrow).ParseLine(bad)
+		require.Error(t, err, bad)
+	}
+	// with five throws, the second column has more codes than outcomes
+	gd := Guide{Game: RockPaperScissorsLizardSpock, Theirs: "ABCDE", Mine: "VWXYZ", Meaning: MeansOutcome}
+	_, err := gd.ParseLine("E Y")
+	require.Error(t, err)
+	r, err := gd.ParseLine("E X")
+	require.NoError(t, err)
+	require.Equal(t, Round{Theirs: 4, Mine: 0, Outcome: Win}, r)
+}
+
+func TestTally_Panel(t *testing.T) {
+	tally := NewTally(RockPaperScissors, nil)
+	tally.Add(Round{Theirs: 2, Mine: 0, Outcome: Win})
+	cnv := &canvas.Canvas{}
+	r := tally.Panel(cnv, 0, 0)
+	require.Equal(t, r.Dx(), cnv.Rect().Dx())
+	require.Equal(t, "rock", string([]rune{cnv.Pix[0][1].Value, cnv.Pix[0][2].Value, cnv.Pix[0][3].Value, cnv.Pix[0][4].Value}))
+}
@@ -0,0 +1,274 @@
+package set
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// BitSet is a set of integers in [0, 64), stored as a single word. It's a
+// value type, so it makes a cheap, comparable memo key; methods that change
+// the set return the new set.
+type BitSet uint64
+
+// Set returns b with i added. It panics if i is outside [0, 64).
+func (b BitSet) Set(i int) BitSet {
+	if i < 0 || i >= 64 {
+		panic(fmt.Sprintf("set.BitSet.Set: %d is outside [0, 64)", i))
+	}
+	return b | 1<<i
+}
+
+// Clear returns b without i. Integers outside [0, 64) are never members, so
+// clearing one returns b unchanged.
+func (b BitSet) Clear(i int) BitSet {
+	if i < 0 || i >= 64 {
+		return b
+	}
+	return b &^ (1 << i)
+}
+
+// Has reports whether i is a member of b, which it never is if it's outside
+// [0, 64).
+func (b BitSet) Has(i int) bool {
+	return i >= 0 && i < 64 && b&(1<<i) != 0
+}
+
+func (b BitSet) Count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+func (b BitSet) Union(o BitSet) BitSet {
+	return b | o
+}
+
+func (b BitSet) Intersect(o BitSet) BitSet {
+	return b & o
+}
+
+func (b BitSet) Difference(o BitSet) BitSet {
+	return b &^ o
+}
+
+// IsSubset returns true if every member of b is also in o.
+func (b BitSet) IsSubset(o BitSet) bool {
+	return b&^o == 0
+}
+
+// Iter calls f for each member of b in ascending order, stopping early if f
+// returns true.
+func (b BitSet) Iter(f func(i int) (stop bool)) {
+	for b != 0 {
+		i := bits.TrailingZeros64(uint64(b))
+		if f(i) {
+			return
+		}
+		b &= b - 1
+	}
+}
+
+// Items returns the members of b in ascending order.
+func (b BitSet) Items() []int {
+	ret := make([]int, 0, b.Count())
+	b.Iter(func(i int) bool {
+		ret = append(ret, i)
+		return false
+	})
+	return ret
+}
+
+// Subsets calls f for every subset of b, including the empty set and b itself,
+// stopping early if f returns true. Subsets are visited in decreasing numeric
+// order, starting with b.
+func (b BitSet) Subsets(f func(BitSet) (stop bool)) {
+	sub := b
+	for {
+		if f(sub) {
+			return
+		}
+		if sub == 0 {
+			return
+		}
+		sub = (sub - 1) & b
+	}
+}
+
+func (b BitSet) String() string {
+	var parts []string
+	b.Iter(func(i int) bool {
+		parts = append(parts, fmt.Sprint(i))
+		return false
+	})
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Bits is a set of non-negative integers of any size, stored as a bit vector.
+// Unlike BitSet it's not comparable; use Key for memo keys.
+type Bits []uint64
+
+func (b *Bits) Set(i int) {
+	for len(*b) <= i/64 {
+		*b = append(*b, 0)
+	}
+	(*b)[i/64] |= 1 << (i % 64)
+}
+
+func (b Bits) Clear(i int) {
+	if i/64 < len(b) {
+		b[i/64] &^= 1 << (i % 64)
+	}
+}
+
+func (b Bits) Has(i int) bool {
+	return i/64 < len(b) && b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b Bits) Count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+func (b Bits) Copy() Bits {
+	ret := make(Bits, len(b))
+	copy(ret, b)
+	return ret
+}
+
+func (b Bits) Union(o Bits) Bits {
+	if len(o) > len(b) {
+		b, o = o, b
+	}
+	ret := b.Copy()
+	for i, w := range o {
+		ret[i] |= w
+	}
+	return ret
+}
+
+func (b Bits) Intersect(o Bits) Bits {
+	if len(o) < len(b) {
+		b, o = o, b
+	}
+	ret := b.Copy()
+	for i := range ret {
+		ret[i] &= o[i]
+	}
+	return ret
+}
+
+func (b Bits) Difference(o Bits) Bits {
+	ret := b.Copy()
+	for i := range ret {
+		if i < len(o) {
+			ret[i] &^= o[i]
+		}
+	}
+	return ret
+}
+
+// Equal returns true if b and o have the same members, regardless of how many
+// trailing zero words either has.
+func (b Bits) Equal(o Bits) bool {
+	if len(o) > len(b) {
+		b, o = o, b
+	}
+	for i, w := range b {
+		if i < len(o) && w != o[i] || i >= len(o) && w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Iter calls f for each member of b in ascending order, stopping early if f
+// returns true.
+func (b Bits) Iter(f func(i int) (stop bool)) {
+	for wi, w := range b {
+		stop := false
+		BitSet(w).Iter(func(i int) bool {
+			stop = f(wi*64 + i)
+			return stop
+		})
+		if stop {
+			return
+		}
+	}
+}
+
+// Key returns a string that's equal for equal Bits, for use as a map key.
+func (b Bits) Key() string {
+	n := len(b)
+	for n > 0 && b[n-1] == 0 {
+		n--
+	}
+	var sb strings.Builder
+	for _, w := range b[:n] {
+		for s := 0; s < 64; s += 8 {
+			sb.WriteByte(byte(w >> s))
+		}
+	}
+	return sb.String()
+}
+
+// Indexer assigns small, dense integers to arbitrary keys, in the order the
+// keys are first seen, so that sets of keys can be stored as a BitSet or Bits.
+// The zero value is ready to use.
+type Indexer[K comparable] struct {
+	index map[K]int
+	keys  []K
+}
+
+// Index returns the index of k, assigning the next one if k is new.
+func (x *Indexer[K]) Index(k K) int {
+	if i, ok := x.index[k]; ok {
+		return i
+	}
+	if x.index == nil {
+		x.index = map[K]int{}
+	}
+	x.index[k] = len(x.keys)
+	x.keys = append(x.keys, k)
+	return len(x.keys) - 1
+}
+
+// Lookup returns the index of k without assigning one.
+func (x *Indexer[K]) Lookup(k K) (int, bool) {
+	i, ok := x.index[k]
+	return i, ok
+}
+
+// Key returns the key with index i.
+func (x *Indexer[K]) Key(i int) K {
+	return x.keys[i]
+}
+
+func (x *Indexer[K]) Len() int {
+	return len(x.keys)
+}
+
+// BitSet returns the BitSet of the given keys, assigning indices as needed. It
+// panics if any index would be 64 or more.
+func (x *Indexer[K]) BitSet(keys ...K) BitSet {
+	var ret BitSet
+	for _, k := range keys {
+		i := x.Index(k)
+		if i >= 64 {
+			panic(fmt.Sprintf("index %d of %v does not fit in a BitSet", i, k))
+		}
+		ret = ret.Set(i)
+	}
+	return ret
+}
+
+// Keys returns the keys of the members of b, in index order.
+func (x *Indexer[K]) Keys(b BitSet) []K {
+	ret := make([]K, 0, b.Count())
+	b.Iter(func(i int) bool {
+		ret = append(ret, x.keys[i])
+		return false
+	})
+	return ret
+}
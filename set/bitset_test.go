@@ -0,0 +1,74 @@
+package set
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitSet(t *testing.T) {
+	var b BitSet
+	b = b.Set(1).Set(5).Set(63)
+	require.True(t, b.Has(63))
+	require.False(t, b.Has(2))
+	require.Equal(t, 3, b.Count())
+	require.Equal(t, []int{1, 5, 63}, b.Items())
+	require.Equal(t, "{1,5}", b.Clear(63).String())
+	require.False(t, b.Has(64))
+	require.False(t, b.Has(-1))
+	require.Equal(t, b, b.Clear(64))
+	require.Panics(t, func() { b.Set(64) })
+	require.Panics(t, func() { b.Set(-1) })
+
+	o := BitSet(0).Set(5).Set(6)
+	require.Equal(t, []int{1, 5, 6, 63}, b.Union(o).Items())
+	require.Equal(t, []int{5}, b.Intersect(o).Items())
+	require.Equal(t, []int{1, 63}, b.Difference(o).Items())
+	require.True(t, BitSet(0).Set(5).IsSubset(o))
+	require.False(t, b.IsSubset(o))
+
+	var subsets []BitSet
+	BitSet(0b1010).Subsets(func(s BitSet) bool {
+		subsets = append(subsets, s)
+		return false
+	})
+	require.Equal(t, []BitSet{0b1010, 0b1000, 0b0010, 0}, subsets)
+}
+
+func TestBits(t *testing.T) {
+	var b Bits
+	b.Set(3)
+	b.Set(130)
+	require.Len(t, b, 3)
+	require.True(t, b.Has(130))
+	require.False(t, b.Has(1000))
+	require.Equal(t, 2, b.Count())
+
+	var o Bits
+	o.Set(3)
+	o.Set(64)
+	var items []int
+	b.Union(o).Iter(func(i int) bool {
+		items = append(items, i)
+		return false
+	})
+	require.Equal(t, []int{3, 64, 130}, items)
+	require.True(t, b.Intersect(o).Equal(Bits{1 << 3}))
+	require.True(t, b.Difference(o).Equal(Bits{0, 0, 1 << 2}))
+
+	b.Clear(130)
+	require.True(t, b.Equal(Bits{1 << 3}))
+	require.Equal(t, b.Key(), Bits{1 << 3}.Key())
+	require.NotEqual(t, b.Key(), o.Key())
+}
+
+func TestIndexer(t *testing.T) {
+	var x Indexer[string]
+	open := x.BitSet("AA", "DD")
+	open = open.Set(x.Index("BB"))
+	require.Equal(t, 3, x.Len())
+	require.Equal(t, []string{"AA", "DD", "BB"}, x.Keys(open))
+	_, ok := x.Lookup("CC")
+	require.False(t, ok)
+	require.Equal(t, "DD", x.Key(1))
+}
@@ -0,0 +1,206 @@
+package set
+
+import (
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Counter is a multiset: it counts how many of each item it holds. Items whose
+// count drops to zero are removed.
+type Counter[K comparable] map[K]int
+
+// CounterOf returns a Counter of the given items.
+func CounterOf[K comparable](items ...K) Counter[K] {
+	ret := Counter[K]{}
+	ret.Add(items...)
+	return ret
+}
+
+// Add adds one of each of the items.
+func (c Counter[K]) Add(items ...K) {
+	for _, k := range items {
+		c[k]++
+	}
+}
+
+// AddN adds n of k; a negative n removes them.
+func (c Counter[K]) AddN(k K, n int) {
+	c[k] += n
+	if c[k] <= 0 {
+		delete(c, k)
+	}
+}
+
+// Remove removes one of each of the items, if present.
+func (c Counter[K]) Remove(items ...K) {
+	for _, k := range items {
+		c.AddN(k, -1)
+	}
+}
+
+func (c Counter[K]) Count(k K) int {
+	return c[k]
+}
+
+// Total returns the sum of all counts.
+func (c Counter[K]) Total() int {
+	n := 0
+	for _, v := range c {
+		n += v
+	}
+	return n
+}
+
+func (c Counter[K]) Copy() Counter[K] {
+	ret := make(Counter[K], len(c))
+	for k, v := range c {
+		ret[k] = v
+	}
+	return ret
+}
+
+// Plus returns a Counter holding the sum of c's and o's counts.
+func (c Counter[K]) Plus(o Counter[K]) Counter[K] {
+	ret := c.Copy()
+	for k, v := range o {
+		ret.AddN(k, v)
+	}
+	return ret
+}
+
+// Minus returns a Counter holding c's counts less o's, dropping any that reach
+// zero or less.
+func (c Counter[K]) Minus(o Counter[K]) Counter[K] {
+	ret := c.Copy()
+	for k, v := range o {
+		if _, ok := ret[k]; ok {
+			ret.AddN(k, -v)
+		}
+	}
+	return ret
+}
+
+// Intersect returns a Counter holding the minimum of c's and o's counts.
+func (c Counter[K]) Intersect(o Counter[K]) Counter[K] {
+	ret := Counter[K]{}
+	for k, v := range c {
+		if ov := o[k]; ov < v {
+			v = ov
+		}
+		if v > 0 {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+// Union returns a Counter holding the maximum of c's and o's counts.
+func (c Counter[K]) Union(o Counter[K]) Counter[K] {
+	ret := c.Copy()
+	for k, v := range o {
+		if v > ret[k] {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+// Set returns the distinct items in c.
+func (c Counter[K]) Set() Set[K] {
+	ret := Set[K]{}
+	for k := range c {
+		ret[k] = true
+	}
+	return ret
+}
+
+// Entry is an item and its count.
+type Entry[K comparable] struct {
+	Key   K
+	Count int
+}
+
+// MostCommonFunc returns the n items with the highest counts, highest first,
+// breaking ties with less. If n is negative, all items are returned.
+func (c Counter[K]) MostCommonFunc(n int, less func(a, b K) bool) []Entry[K] {
+	ret := make([]Entry[K], 0, len(c))
+	for k, v := range c {
+		ret = append(ret, Entry[K]{k, v})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count != ret[j].Count {
+			return ret[i].Count > ret[j].Count
+		}
+		return less(ret[i].Key, ret[j].Key)
+	})
+	if n >= 0 && n < len(ret) {
+		ret = ret[:n]
+	}
+	return ret
+}
+
+// MostCommon returns the n items with the highest counts, highest first, with
+// ties in ascending order. If n is negative, all items are returned.
+func MostCommon[K constraints.Ordered](c Counter[K], n int) []Entry[K] {
+	return c.MostCommonFunc(n, func(a, b K) bool { return a < b })
+}
+
+// WindowCounter counts the items in a sliding window over a sequence, keeping
+// the number of distinct items current in O(1) per step.
+type WindowCounter[K comparable] struct {
+	Counter Counter[K]
+	size    int
+	ring    []K
+	head    int
+}
+
+// NewWindowCounter returns a WindowCounter for windows of the given size, which
+// must be positive.
+func NewWindowCounter[K comparable](size int) *WindowCounter[K] {
+	if size < 1 {
+		panic("set.NewWindowCounter: size must be positive")
+	}
+	return &WindowCounter[K]{
+		Counter: Counter[K]{},
+		size:    size,
+		ring:    make([]K, 0, size),
+	}
+}
+
+// Push adds k to the window, evicting the oldest item if the window is full.
+// It returns the evicted item, if any.
+func (w *WindowCounter[K]) Push(k K) (evicted K, ok bool) {
+	if len(w.ring) < w.size {
+		w.ring = append(w.ring, k)
+		w.Counter.Add(k)
+		return evicted, false
+	}
+
+	evicted = w.ring[w.head]
+	w.Counter.Remove(evicted)
+	w.ring[w.head] = k
+	w.head = (w.head + 1) % w.size
+	w.Counter.Add(k)
+	return evicted, true
+}
+
+// Len returns the number of items in the window.
+func (w *WindowCounter[K]) Len() int {
+	return len(w.ring)
+}
+
+// Full returns true once the window holds size items.
+func (w *WindowCounter[K]) Full() bool {
+	return len(w.ring) == w.size
+}
+
+// Distinct returns the number of distinct items in the window.
+func (w *WindowCounter[K]) Distinct() int {
+	return len(w.Counter)
+}
+
+// AllDistinct returns true if the window is full and no item in it repeats.
+func (w *WindowCounter[K]) AllDistinct() bool {
+	return w.Full() && w.Distinct() == w.size
+}
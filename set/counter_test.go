@@ -0,0 +1,71 @@
+package set
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounter(t *testing.T) {
+	c := CounterOf([]rune("abracadabra")...)
+	require.Equal(t, 5, c.Count('a'))
+	require.Equal(t, 11, c.Total())
+	require.Equal(t, []Entry[rune]{{'a', 5}, {'b', 2}, {'r', 2}}, MostCommon(c, 3))
+
+	c.Remove('a', 'c', 'z')
+	require.Equal(t, 0, c.Count('c'))
+	require.NotContains(t, c, 'c')
+	require.Len(t, MostCommon(c, -1), 4)
+
+	a := CounterOf("x", "x", "y")
+	b := CounterOf("x", "y", "y", "z")
+	require.Equal(t, Counter[string]{"x": 3, "y": 3, "z": 1}, a.Plus(b))
+	require.Equal(t, Counter[string]{"x": 1}, a.Minus(b))
+	require.Equal(t, Counter[string]{"x": 1, "y": 1}, a.Intersect(b))
+	require.Equal(t, Counter[string]{"x": 2, "y": 2, "z": 1}, a.Union(b))
+}
+
+func TestWindowCounter(t *testing.T) {
+	// AoC 2022 day 6
+	firstMarker := func(s string, size int) int {
+		w := NewWindowCounter[rune](size)
+		for i, r := range s {
+			w.Push(r)
+			if w.AllDistinct() {
+				return i + 1
+			}
+		}
+		return -1
+	}
+
+	tests := []struct {
+		in      string
+		packet  int
+		message int
+	}{
+		{"mjqjpqmgbljsphdztnvjfqwrcgsmlb", 7, 19},
+		{"bvwbjplbgvbhsrlpgdmjqwftvncz", 5, 23},
+		{"nppdvjthqldpwncqszvftbrmjlhg", 6, 23},
+		{"nznrnfrfntjfmvfwmzdfjlvtqnbhcprsg", 10, 29},
+		{"zcfzfwzzqfrljwzlrfnpqdbhtmscgvjw", 11, 26},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			require.Equal(t, tt.packet, firstMarker(tt.in, 4))
+			require.Equal(t, tt.message, firstMarker(tt.in, 14))
+		})
+	}
+
+	w := NewWindowCounter[int](2)
+	_, ok := w.Push(1)
+	require.False(t, ok)
+	w.Push(2)
+	evicted, ok := w.Push(2)
+	require.True(t, ok)
+	require.Equal(t, 1, evicted)
+	require.Equal(t, 1, w.Distinct())
+	require.Equal(t, 2, w.Len())
+
+	require.Panics(t, func() { NewWindowCounter[int](0) })
+	require.Panics(t, func() { NewWindowCounter[int](-1) })
+}
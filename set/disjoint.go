@@ -0,0 +1,103 @@
+package set
+
+// DisjointSet is a union-find structure over items of type K, with path
+// compression and union by rank. Items are added implicitly the first time
+// they're seen. The zero value is ready to use.
+type DisjointSet[K comparable] struct {
+	index  map[K]int
+	items  []K
+	parent []int
+	rank   []uint8
+	count  int
+}
+
+// Add adds the items as singleton groups, if they're not already present.
+func (d *DisjointSet[K]) Add(items ...K) {
+	for _, k := range items {
+		d.id(k)
+	}
+}
+
+func (d *DisjointSet[K]) id(k K) int {
+	if i, ok := d.index[k]; ok {
+		return i
+	}
+	if d.index == nil {
+		d.index = map[K]int{}
+	}
+	i := len(d.items)
+	d.index[k] = i
+	d.items = append(d.items, k)
+	d.parent = append(d.parent, i)
+	d.rank = append(d.rank, 0)
+	d.count++
+	return i
+}
+
+func (d *DisjointSet[K]) root(i int) int {
+	r := i
+	for d.parent[r] != r {
+		r = d.parent[r]
+	}
+	for d.parent[i] != r {
+		d.parent[i], i = r, d.parent[i]
+	}
+	return r
+}
+
+// Find returns the representative item of k's group.
+func (d *DisjointSet[K]) Find(k K) K {
+	return d.items[d.root(d.id(k))]
+}
+
+// Union merges the groups containing a and b, returning true if they were
+// previously separate.
+func (d *DisjointSet[K]) Union(a, b K) bool {
+	ra, rb := d.root(d.id(a)), d.root(d.id(b))
+	if ra == rb {
+		return false
+	}
+	if d.rank[ra] < d.rank[rb] {
+		ra, rb = rb, ra
+	}
+	d.parent[rb] = ra
+	if d.rank[ra] == d.rank[rb] {
+		d.rank[ra]++
+	}
+	d.count--
+	return true
+}
+
+// Same returns true if a and b are in the same group.
+func (d *DisjointSet[K]) Same(a, b K) bool {
+	return d.root(d.id(a)) == d.root(d.id(b))
+}
+
+// Count returns the number of groups.
+func (d *DisjointSet[K]) Count() int {
+	return d.count
+}
+
+// Len returns the number of items.
+func (d *DisjointSet[K]) Len() int {
+	return len(d.items)
+}
+
+// Groups returns the items of each group. Groups are ordered by when their
+// first item was added, and items within a group are in the order they were
+// added.
+func (d *DisjointSet[K]) Groups() [][]K {
+	var ret [][]K
+	group := map[int]int{}
+	for i, k := range d.items {
+		r := d.root(i)
+		g, ok := group[r]
+		if !ok {
+			g = len(ret)
+			group[r] = g
+			ret = append(ret, nil)
+		}
+		ret[g] = append(ret[g], k)
+	}
+	return ret
+}
@@ -0,0 +1,28 @@
+package set
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisjointSet(t *testing.T) {
+	var d DisjointSet[string]
+	d.Add("a", "b", "c", "d", "e")
+	require.Equal(t, 5, d.Count())
+
+	require.True(t, d.Union("a", "c"))
+	require.True(t, d.Union("d", "e"))
+	require.True(t, d.Union("e", "c"))
+	require.False(t, d.Union("a", "d"))
+	require.Equal(t, 2, d.Count())
+
+	require.True(t, d.Same("a", "e"))
+	require.False(t, d.Same("a", "b"))
+	require.Equal(t, d.Find("a"), d.Find("d"))
+
+	d.Union("f", "g")
+	require.Equal(t, 3, d.Count())
+	require.Equal(t, 7, d.Len())
+	require.Equal(t, [][]string{{"a", "c", "d", "e"}, {"b"}, {"f", "g"}}, d.Groups())
+}
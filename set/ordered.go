@@ -0,0 +1,139 @@
+package set
+
+// OrderedSet is a set that remembers the order in which items were first
+// added, so iterating over it is deterministic. The zero value is an empty set
+// ready to use.
+type OrderedSet[K comparable] struct {
+	index map[K]int
+	items []K
+	// live[i] is false if items[i] has been removed; removed counts how many.
+	live    []bool
+	removed int
+}
+
+// NewOrdered returns an OrderedSet holding the given items, in order.
+func NewOrdered[K comparable](items ...K) *OrderedSet[K] {
+	ret := &OrderedSet[K]{}
+	ret.Add(items...)
+	return ret
+}
+
+// Add adds any items not already in the set to the end of it. Re-adding an item
+// does not change its position.
+func (o *OrderedSet[K]) Add(items ...K) {
+	if o.index == nil {
+		o.index = map[K]int{}
+	}
+	for _, k := range items {
+		if _, ok := o.index[k]; ok {
+			continue
+		}
+		o.index[k] = len(o.items)
+		o.items = append(o.items, k)
+		o.live = append(o.live, true)
+	}
+}
+
+// Remove removes the items from the set.
+func (o *OrderedSet[K]) Remove(items ...K) {
+	for _, k := range items {
+		i, ok := o.index[k]
+		if !ok {
+			continue
+		}
+		delete(o.index, k)
+		o.live[i] = false
+		o.removed++
+	}
+	if o.removed > len(o.items)/2 {
+		o.compact()
+	}
+}
+
+// compact drops removed items from the backing slices.
+func (o *OrderedSet[K]) compact() {
+	j := 0
+	for i, k := range o.items {
+		if !o.live[i] {
+			continue
+		}
+		o.items[j] = k
+		o.live[j] = true
+		o.index[k] = j
+		j++
+	}
+	var zero K
+	for i := j; i < len(o.items); i++ {
+		o.items[i] = zero
+	}
+	o.items = o.items[:j]
+	o.live = o.live[:j]
+	o.removed = 0
+}
+
+func (o *OrderedSet[K]) Has(k K) bool {
+	_, ok := o.index[k]
+	return ok
+}
+
+func (o *OrderedSet[K]) Len() int {
+	return len(o.index)
+}
+
+// Each calls f on each item in insertion order, stopping early if f returns
+// true.
+func (o *OrderedSet[K]) Each(f func(K) (stop bool)) {
+	for i, k := range o.items {
+		if o.live[i] && f(k) {
+			return
+		}
+	}
+}
+
+// Items returns the items in insertion order.
+func (o *OrderedSet[K]) Items() []K {
+	ret := make([]K, 0, o.Len())
+	o.Each(func(k K) bool {
+		ret = append(ret, k)
+		return false
+	})
+	return ret
+}
+
+// Set returns the items as an unordered Set.
+func (o *OrderedSet[K]) Set() Set[K] {
+	return FromItems(o.Items())
+}
+
+func (o *OrderedSet[K]) Copy() *OrderedSet[K] {
+	return NewOrdered(o.Items()...)
+}
+
+// Union returns the items of o, in order, followed by those of b not in o.
+func (o *OrderedSet[K]) Union(b *OrderedSet[K]) *OrderedSet[K] {
+	ret := o.Copy()
+	ret.Add(b.Items()...)
+	return ret
+}
+
+// Intersect returns the items of o that are also in b, in o's order.
+func (o *OrderedSet[K]) Intersect(b *OrderedSet[K]) *OrderedSet[K] {
+	return o.Filter(b.Has)
+}
+
+// Difference returns the items of o that are not in b, in o's order.
+func (o *OrderedSet[K]) Difference(b *OrderedSet[K]) *OrderedSet[K] {
+	return o.Filter(func(k K) bool { return !b.Has(k) })
+}
+
+// Filter returns the items of o for which keep returns true, in o's order.
+func (o *OrderedSet[K]) Filter(keep func(K) bool) *OrderedSet[K] {
+	ret := &OrderedSet[K]{}
+	o.Each(func(k K) bool {
+		if keep(k) {
+			ret.Add(k)
+		}
+		return false
+	})
+	return ret
+}
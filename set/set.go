@@ -7,6 +7,8 @@ import (
 	"golang.org/x/exp/constraints"
 )
 
+// Set is a set of items, stored as keys mapped to true. Use Add and Remove
+// rather than setting keys to false: the methods treat every key as an item.
 type Set[K comparable] map[K]bool
 
 func (a Set[K]) Intersect(b Set[K]) Set[K] {
@@ -71,6 +73,97 @@ func (a Set[K]) Item() K {
 	return zero
 }
 
+// ItemsFunc returns the items in the set sorted by less, for keys that aren't
+// constraints.Ordered.
+func (a Set[K]) ItemsFunc(less func(a, b K) bool) []K {
+	ret := make([]K, 0, len(a))
+	for k := range a {
+		ret = append(ret, k)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return less(ret[i], ret[j])
+	})
+	return ret
+}
+
+// SymmetricDifference returns the items that are in exactly one of a and b.
+func (a Set[K]) SymmetricDifference(b Set[K]) Set[K] {
+	ret := Set[K]{}
+	for k := range a {
+		if !b[k] {
+			ret[k] = true
+		}
+	}
+	for k := range b {
+		if !a[k] {
+			ret[k] = true
+		}
+	}
+	return ret
+}
+
+// IsSubset returns true if every item in a is also in b.
+func (a Set[K]) IsSubset(b Set[K]) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset returns true if every item in b is also in a.
+func (a Set[K]) IsSuperset(b Set[K]) bool {
+	return b.IsSubset(a)
+}
+
+// Equal returns true if a and b contain exactly the same items.
+func (a Set[K]) Equal(b Set[K]) bool {
+	return len(a) == len(b) && a.IsSubset(b)
+}
+
+// Add adds the items to the set.
+func (a Set[K]) Add(items ...K) {
+	for _, k := range items {
+		a[k] = true
+	}
+}
+
+// Remove removes the items from the set.
+func (a Set[K]) Remove(items ...K) {
+	for _, k := range items {
+		delete(a, k)
+	}
+}
+
+// Has returns true if k is in the set.
+func (a Set[K]) Has(k K) bool {
+	return a[k]
+}
+
+// Filter returns a new set of the items for which keep returns true.
+func (a Set[K]) Filter(keep func(K) bool) Set[K] {
+	ret := Set[K]{}
+	for k := range a {
+		if keep(k) {
+			ret[k] = true
+		}
+	}
+	return ret
+}
+
+// Map returns a new set of the results of applying f to each item in a.
+func Map[K, V comparable](a Set[K], f func(K) V) Set[V] {
+	ret := Set[V]{}
+	for k := range a {
+		ret[f(k)] = true
+	}
+	return ret
+}
+
 func FromItems[K comparable](items []K) Set[K] {
 	s := Set[K]{}
 	for _, i := range items {
@@ -0,0 +1,62 @@
+package set
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet_Relations(t *testing.T) {
+	a := FromString("abc")
+	b := FromString("bcd")
+
+	require.Equal(t, []rune("ad"), Items(a.SymmetricDifference(b)))
+	require.False(t, a.IsSubset(b))
+	require.True(t, FromString("bc").IsSubset(a))
+	require.True(t, a.IsSuperset(FromString("ab")))
+	require.True(t, a.IsSubset(a))
+	require.True(t, a.Equal(FromString("cba")))
+	require.False(t, a.Equal(b))
+}
+
+func TestSet_Methods(t *testing.T) {
+	s := Set[int]{}
+	s.Add(3, 1, 2)
+	s.Remove(2, 5)
+	require.True(t, s.Has(1))
+	require.False(t, s.Has(2))
+	require.Equal(t, []int{1, 3}, Items(s))
+
+	require.Equal(t, []int{3}, Items(s.Filter(func(i int) bool { return i > 1 })))
+	require.Equal(t, []string{"1", "3"}, Items(Map(s, func(i int) string { return string(rune('0' + i)) })))
+
+	type pt struct{ x, y int }
+	pts := FromItems([]pt{{2, 1}, {1, 2}, {1, 1}})
+	require.Equal(t, []pt{{1, 1}, {1, 2}, {2, 1}}, pts.ItemsFunc(func(a, b pt) bool {
+		return a.x < b.x || a.x == b.x && a.y < b.y
+	}))
+}
+
+func TestOrderedSet(t *testing.T) {
+	var o OrderedSet[string]
+	o.Add("c", "a", "b", "a")
+	require.Equal(t, []string{"c", "a", "b"}, o.Items())
+	require.Equal(t, 3, o.Len())
+
+	o.Remove("a")
+	o.Add("a")
+	require.Equal(t, []string{"c", "b", "a"}, o.Items())
+
+	o.Remove("c", "b")
+	require.Equal(t, []string{"a"}, o.Items())
+	require.False(t, o.Has("c"))
+	o.Add("d")
+	require.Equal(t, []string{"a", "d"}, o.Items())
+
+	x := NewOrdered(5, 4, 3, 2)
+	y := NewOrdered(1, 2, 3)
+	require.Equal(t, []int{5, 4, 3, 2, 1}, x.Union(y).Items())
+	require.Equal(t, []int{3, 2}, x.Intersect(y).Items())
+	require.Equal(t, []int{5, 4}, x.Difference(y).Items())
+	require.True(t, x.Set().Equal(FromItems([]int{2, 3, 4, 5})))
+}
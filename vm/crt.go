@@ -0,0 +1,118 @@
+package vm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/asymmetricia/aoc22/aoc"
+	"github.com/asymmetricia/aoc22/canvas"
+)
+
+// CRT is a frame buffer drawn one pixel per cycle, left to right and top to
+// bottom, lighting each pixel if a three-pixel-wide sprite centered on a
+// register's value covers it, as in AoC 2022 day 10.
+type CRT struct {
+	Width, Height int
+	Pixels        []bool
+
+	// Register holds the sprite's horizontal position.
+	Register string
+}
+
+// NewCRT returns a blank width by height CRT whose sprite follows register.
+// Both dimensions must be positive.
+func NewCRT(width, height int, register string) *CRT {
+	if width < 1 || height < 1 {
+		panic("vm.NewCRT: width and height must be positive")
+	}
+	return &CRT{
+		Width:    width,
+		Height:   height,
+		Pixels:   make([]bool, width*height),
+		Register: register,
+	}
+}
+
+// Hook draws the current cycle's pixel; add it to a Machine's Hooks. It does
+// nothing if the CRT has no pixels.
+func (c *CRT) Hook(m *Machine) {
+	if len(c.Pixels) == 0 || c.Width < 1 {
+		return
+	}
+	i := (m.Cycle - 1) % len(c.Pixels)
+	x := i % c.Width
+	sprite := m.Registers[c.Register]
+	c.Pixels[i] = x >= sprite-1 && x <= sprite+1
+}
+
+// Rows returns the frame buffer as rows of pixels, e.g. for aoc.OCR.
+func (c *CRT) Rows() [][]bool {
+	ret := make([][]bool, c.Height)
+	for y := range ret {
+		ret[y] = c.Pixels[y*c.Width : (y+1)*c.Width]
+	}
+	return ret
+}
+
+func (c *CRT) String() string {
+	sb := &strings.Builder{}
+	for _, row := range c.Rows() {
+		for _, px := range row {
+			if px {
+				sb.WriteRune('#')
+			} else {
+				sb.WriteRune('.')
+			}
+		}
+		sb.WriteRune('\n')
+	}
+	return sb.String()
+}
+
+// Canvas renders the CRT in a frame, alongside the machine's cycle count and
+// registers.
+func (c *CRT) Canvas(m *Machine) *canvas.Canvas {
+	cnv := &canvas.Canvas{}
+	canvas.TextBox{
+		Title:      []rune("Elfosonic"),
+		FrameColor: aoc.TolVibrantBlue,
+		Width:      c.Width,
+		Height:     c.Height,
+	}.On(cnv)
+	for y, row := range c.Rows() {
+		for x, px := range row {
+			if px {
+				cnv.PrintAt(x+1, y+1, "#", aoc.TolVibrantTeal)
+			} else {
+				cnv.PrintAt(x+1, y+1, ".", aoc.TolVibrantGrey)
+			}
+		}
+	}
+
+	canvas.TextBox{
+		Left:  c.Width + 2,
+		Title: []rune("Cycle"),
+		Body:  []rune(strconv.Itoa(m.Cycle)),
+		Width: 6,
+	}.On(cnv)
+	for i, name := range sortedKeys(m.Registers) {
+		canvas.TextBox{
+			Top:   2 * (i + 1),
+			Left:  c.Width + 2,
+			Title: []rune(name),
+			Body:  []rune(strconv.Itoa(m.Registers[name])),
+			Width: 6,
+		}.On(cnv)
+	}
+	return cnv
+}
+
+// Frames returns a hook that captures a Canvas of the CRT every cycle, and a
+// pointer to the captured frames, for canvas.RenderGif. Add it to the
+// Machine's Hooks after c.Hook, so each frame includes that cycle's pixel.
+func (c *CRT) Frames() (hook func(m *Machine), frames *[]*canvas.Canvas) {
+	frames = &[]*canvas.Canvas{}
+	return func(m *Machine) {
+		*frames = append(*frames, c.Canvas(m))
+	}, frames
+}
@@ -0,0 +1,280 @@
+// Package vm is a small, configurable virtual machine for "elf CPU" puzzles:
+// programs of simple instructions, each taking some number of cycles, with
+// hooks that observe the machine during every cycle.
+package vm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Arg is an instruction argument: either a register name or an immediate.
+type Arg struct {
+	Reg string
+	Imm int
+}
+
+func (a Arg) String() string {
+	if a.Reg != "" {
+		return a.Reg
+	}
+	return strconv.Itoa(a.Imm)
+}
+
+type Instruction struct {
+	Op   string
+	Args []Arg
+}
+
+func (in Instruction) String() string {
+	parts := []string{in.Op}
+	for _, a := range in.Args {
+		parts = append(parts, a.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+type Program []Instruction
+
+// Parse reads a program with one instruction per line: an op name followed by
+// whitespace-separated arguments. Arguments that parse as integers are
+// immediates; anything else is a register name.
+func Parse(r io.Reader) (Program, error) {
+	var ret Program
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		in := Instruction{Op: fields[0]}
+		for _, f := range fields[1:] {
+			if i, err := strconv.Atoi(f); err == nil {
+				in.Args = append(in.Args, Arg{Imm: i})
+			} else {
+				in.Args = append(in.Args, Arg{Reg: f})
+			}
+		}
+		ret = append(ret, in)
+	}
+	return ret, scanner.Err()
+}
+
+// ParseString is Parse for a string.
+func ParseString(s string) (Program, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// Op defines an instruction.
+type Op struct {
+	// Cycles is how many cycles the instruction takes. Hooks see the machine
+	// state from before the instruction during all of them.
+	Cycles int
+
+	// Args is the number of arguments the instruction requires.
+	Args int
+
+	// Exec applies the instruction at the end of its last cycle. m.PC has
+	// already been advanced to the next instruction; jumps can change it.
+	Exec func(m *Machine, args []Arg) error
+}
+
+// InstructionSet maps op names to their definitions.
+type InstructionSet map[string]Op
+
+// Day10 is the instruction set of the handheld device's CPU from AoC 2022 day
+// 10, with a single register "x".
+var Day10 = InstructionSet{
+	"noop": {Cycles: 1},
+	"addx": {Cycles: 2, Args: 1, Exec: func(m *Machine, args []Arg) error {
+		v, err := m.Value(args[0])
+		if err != nil {
+			return err
+		}
+		m.Registers["x"] += v
+		return nil
+	}},
+}
+
+type StopReason int
+
+const (
+	// Halted means the program counter left the program.
+	Halted StopReason = iota
+	// Until means the until function returned true.
+	Until
+	// Breakpoint means the next instruction is at a breakpoint.
+	Breakpoint
+)
+
+func (s StopReason) String() string {
+	switch s {
+	case Halted:
+		return "halted"
+	case Until:
+		return "until"
+	case Breakpoint:
+		return "breakpoint"
+	}
+	return fmt.Sprintf("(bad stop reason %d)", int(s))
+}
+
+// Stats describes a call to Run.
+type Stats struct {
+	Reason       StopReason
+	Cycles       int
+	Instructions int
+	OpCounts     map[string]int
+}
+
+// Machine executes programs. Registers, ISA and any hooks should be set up
+// before calling Run.
+type Machine struct {
+	ISA       InstructionSet
+	Registers map[string]int
+
+	// PC is the index of the next instruction to execute.
+	PC int
+
+	// Cycle is the number of the current cycle while hooks are running (the
+	// first cycle is 1), and the number of completed cycles otherwise.
+	Cycle int
+
+	// Hooks are called during every cycle.
+	Hooks []func(m *Machine)
+
+	// Breakpoints stops Run before executing the instructions at these
+	// addresses. Run can be called again to continue.
+	Breakpoints map[int]bool
+
+	// If Trace is set, each instruction is written to it as it's executed.
+	Trace io.Writer
+
+	program Program
+	resumed bool
+}
+
+// New returns a machine for the given instruction set, with the given initial
+// register values.
+func New(isa InstructionSet, registers map[string]int) *Machine {
+	if registers == nil {
+		registers = map[string]int{}
+	}
+	return &Machine{ISA: isa, Registers: registers}
+}
+
+// Value returns the value of an argument: the register's contents, or the
+// immediate.
+func (m *Machine) Value(a Arg) (int, error) {
+	if a.Reg == "" {
+		return a.Imm, nil
+	}
+	v, ok := m.Registers[a.Reg]
+	if !ok {
+		return 0, fmt.Errorf("unknown register %q", a.Reg)
+	}
+	return v, nil
+}
+
+// Load sets the program to execute and resets the program counter and cycle
+// count. Registers are left alone.
+func (m *Machine) Load(p Program) {
+	m.program = p
+	m.PC = 0
+	m.Cycle = 0
+	m.resumed = false
+}
+
+// Step executes the next instruction.
+func (m *Machine) Step() error {
+	if m.PC < 0 || m.PC >= len(m.program) {
+		return fmt.Errorf("pc %d outside program of length %d", m.PC, len(m.program))
+	}
+	in := m.program[m.PC]
+	op, ok := m.ISA[in.Op]
+	if !ok {
+		return fmt.Errorf("pc %d: unknown op %q", m.PC, in.Op)
+	}
+	if len(in.Args) != op.Args {
+		return fmt.Errorf("pc %d: %s takes %d argument(s), got %d", m.PC, in.Op, op.Args, len(in.Args))
+	}
+
+	if m.Trace != nil {
+		fmt.Fprintf(m.Trace, "%6d %4d %-20s %s\n", m.Cycle+1, m.PC, in, m.registerString())
+	}
+
+	for i := 0; i < op.Cycles; i++ {
+		m.Cycle++
+		for _, hook := range m.Hooks {
+			hook(m)
+		}
+	}
+
+	pc := m.PC
+	m.PC++
+	if op.Exec != nil {
+		if err := op.Exec(m, in.Args); err != nil {
+			return fmt.Errorf("pc %d: %s: %w", pc, in, err)
+		}
+	}
+	return nil
+}
+
+// Run executes p from the start, or continues the current program if p is nil,
+// until the program halts, until returns true (it's checked before each
+// instruction; nil means never), or a breakpoint is reached.
+func (m *Machine) Run(p Program, until func(m *Machine) bool) (Stats, error) {
+	if p != nil {
+		m.Load(p)
+	}
+
+	stats := Stats{OpCounts: map[string]int{}}
+	startCycle := m.Cycle
+	for {
+		stats.Cycles = m.Cycle - startCycle
+		if m.PC < 0 || m.PC >= len(m.program) {
+			stats.Reason = Halted
+			return stats, nil
+		}
+		if until != nil && until(m) {
+			stats.Reason = Until
+			return stats, nil
+		}
+		if m.Breakpoints[m.PC] && !m.resumed {
+			m.resumed = true
+			stats.Reason = Breakpoint
+			return stats, nil
+		}
+		m.resumed = false
+
+		op := m.program[m.PC].Op
+		if err := m.Step(); err != nil {
+			stats.Cycles = m.Cycle - startCycle
+			return stats, err
+		}
+		stats.Instructions++
+		stats.OpCounts[op]++
+	}
+}
+
+func sortedKeys(registers map[string]int) []string {
+	names := make([]string, 0, len(registers))
+	for name := range registers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *Machine) registerString() string {
+	names := sortedKeys(m.Registers)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%d", name, m.Registers[name])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,106 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMachine_Day10(t *testing.T) {
+	p, err := ParseString("noop\naddx 3\naddx -5\n")
+	require.NoError(t, err)
+
+	m := New(Day10, map[string]int{"x": 1})
+	var during []int
+	m.Hooks = append(m.Hooks, func(m *Machine) {
+		during = append(during, m.Registers["x"])
+	})
+	crt := NewCRT(5, 1, "x")
+	m.Hooks = append(m.Hooks, crt.Hook)
+
+	stats, err := m.Run(p, nil)
+	require.NoError(t, err)
+	require.Equal(t, Stats{
+		Reason:       Halted,
+		Cycles:       5,
+		Instructions: 3,
+		OpCounts:     map[string]int{"noop": 1, "addx": 2},
+	}, stats)
+	require.Equal(t, []int{1, 1, 1, 4, 4}, during)
+	require.Equal(t, -1, m.Registers["x"])
+	require.Equal(t, "#####\n", crt.String())
+
+	require.Panics(t, func() { NewCRT(0, 6, "x") })
+	require.Panics(t, func() { NewCRT(40, 0, "x") })
+	require.NotPanics(t, func() { (&CRT{Register: "x"}).Hook(m) })
+}
+
+func TestMachine_SignalStrength(t *testing.T) {
+	p, err := ParseString(strings.Repeat("addx 1\n", 40))
+	require.NoError(t, err)
+
+	m := New(Day10, map[string]int{"x": 1})
+	signal := 0
+	m.Hooks = append(m.Hooks, func(m *Machine) {
+		if (m.Cycle+20)%40 == 0 {
+			signal += m.Cycle * m.Registers["x"]
+		}
+	})
+	_, err = m.Run(p, nil)
+	require.NoError(t, err)
+	// x is 1 + (cycle-1)/2 during each cycle
+	require.Equal(t, 20*10+60*30, signal)
+}
+
+func TestMachine_Control(t *testing.T) {
+	isa := InstructionSet{
+		"set": {Cycles: 1, Args: 2, Exec: func(m *Machine, args []Arg) error {
+			v, err := m.Value(args[1])
+			m.Registers[args[0].Reg] = v
+			return err
+		}},
+		"dec": {Cycles: 1, Args: 1, Exec: func(m *Machine, args []Arg) error {
+			m.Registers[args[0].Reg]--
+			return nil
+		}},
+		"jnz": {Cycles: 3, Args: 2, Exec: func(m *Machine, args []Arg) error {
+			v, err := m.Value(args[0])
+			if err == nil && v != 0 {
+				m.PC += args[1].Imm - 1
+			}
+			return err
+		}},
+	}
+	p, err := ParseString("set a 3\ndec a\njnz a -1\nset b a\n")
+	require.NoError(t, err)
+
+	m := New(isa, map[string]int{"a": 0, "b": 7})
+	m.Breakpoints = map[int]bool{3: true}
+	trace := &strings.Builder{}
+	m.Trace = trace
+
+	stats, err := m.Run(p, nil)
+	require.NoError(t, err)
+	require.Equal(t, Breakpoint, stats.Reason)
+	require.Equal(t, 3, m.PC)
+	require.Equal(t, 1+3*(1+3), stats.Cycles)
+	require.Equal(t, 7, m.Registers["b"])
+	require.Contains(t, trace.String(), "jnz a -1")
+
+	stats, err = m.Run(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, Halted, stats.Reason)
+	require.Equal(t, 1, stats.Instructions)
+	require.Equal(t, 0, m.Registers["b"])
+
+	stats, err = m.Run(p, func(m *Machine) bool { return m.Cycle >= 4 })
+	require.NoError(t, err)
+	require.Equal(t, Until, stats.Reason)
+	require.Equal(t, 5, stats.Cycles)
+
+	_, err = m.Run(Program{{Op: "dec"}}, nil)
+	require.Error(t, err)
+	_, err = m.Run(Program{{Op: "set", Args: []Arg{{Reg: "a"}, {Reg: "nope"}}}}, nil)
+	require.Error(t, err)
+}